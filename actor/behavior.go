@@ -0,0 +1,107 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: behavior
+ * @Version: 1.0.0
+ * @Date: 2025/1/14 16:05
+ */
+
+package actor
+
+import (
+	"github.com/dingqinghui/gas/api"
+)
+
+// behavior
+// @Description: 当前处理消息的对象及其路由
+type behavior struct {
+	receiver interface{}
+	router   api.IActorRouter
+}
+
+// Become
+// @Description: 切换处理消息的对象,receiver的导出方法作为消息处理函数,Unbecome恢复之前的处理
+// @receiver a
+// @param receiver 必须是导出类型
+// @return *api.Error
+func (a *baseActorContext) Become(receiver interface{}) *api.Error {
+	if receiver == nil {
+		return api.ErrActorBehaviorIsNil
+	}
+	router := a.System().GetOrSetRouter(receiver)
+	a.behaviors = append(a.behaviors, &behavior{receiver: receiver, router: router})
+	return nil
+}
+
+// Unbecome
+// @Description: 恢复上一个处理对象,没有切换过则无效果
+// @receiver a
+func (a *baseActorContext) Unbecome() {
+	if len(a.behaviors) == 0 {
+		return
+	}
+	a.behaviors[len(a.behaviors)-1] = nil
+	a.behaviors = a.behaviors[:len(a.behaviors)-1]
+}
+
+// behavior
+// @Description: 当前的处理对象,默认为actor
+// @receiver a
+// @return interface{}
+// @return api.IActorRouter
+func (a *baseActorContext) behavior() (interface{}, api.IActorRouter) {
+	if n := len(a.behaviors); n > 0 {
+		b := a.behaviors[n-1]
+		return b.receiver, b.router
+	}
+	return a.actor, a.router
+}
+
+// Stash
+// @Description: 缓存当前消息,UnstashAll后按原顺序重新处理
+// @receiver a
+// @return *api.Error
+func (a *baseActorContext) Stash() *api.Error {
+	msg := a.Message()
+	if msg == nil || api.IsSystemMethod(msg.Method) {
+		return api.ErrActorStash
+	}
+	if a.stashed {
+		return nil
+	}
+	a.stash = append(a.stash, msg)
+	a.stashed = true
+	return nil
+}
+
+// UnstashAll
+// @Description: 当前消息处理完后,先于邮箱中的消息处理缓存的消息
+// @receiver a
+func (a *baseActorContext) UnstashAll() {
+	if len(a.stash) == 0 {
+		return
+	}
+	a.unstashed = append(a.stash, a.unstashed...)
+	a.stash = nil
+}
+
+func (a *baseActorContext) invokeUnstashed() {
+	for len(a.unstashed) > 0 {
+		msg := a.unstashed[0]
+		a.unstashed[0] = nil
+		a.unstashed = a.unstashed[1:]
+		_ = a.invoke(msg)
+	}
+}
+
+// clearStash
+// @Description: actor停止,缓存的消息回复错误
+// @receiver a
+func (a *baseActorContext) clearStash() {
+	for _, msg := range append(a.unstashed, a.stash...) {
+		a.System().DeadLetter().Publish(msg, api.ErrActorStopped)
+		_ = msg.Respond(&api.RespondMessage{Err: api.ErrActorStopped})
+	}
+	a.stash = nil
+	a.unstashed = nil
+}
@@ -0,0 +1,100 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: behavior_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/14 16:05
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Room
+// @Description: 初始化后先缓存消息,Ready后按顺序处理
+type Room struct {
+	api.BuiltinActor
+	order chan string
+}
+
+// Loading
+// @Description: Room加载中的处理对象
+type Loading struct {
+	room *Room
+}
+
+func (l *Loading) Chat(_ *string) *api.Error { return l.room.Ctx.Stash() }
+func (l *Loading) Boom() *api.Error          { return l.room.Ctx.Stash() }
+func (l *Loading) Ready() *api.Error {
+	l.room.Ctx.Unbecome()
+	l.room.Ctx.UnstashAll()
+	return nil
+}
+
+func (r *Room) OnInit(ctx api.IActorContext) *api.Error {
+	_ = r.BuiltinActor.OnInit(ctx)
+	return ctx.Become(&Loading{room: r})
+}
+
+func (r *Room) Chat(s *string) (*string, *api.Error) {
+	r.order <- *s
+	return s, nil
+}
+
+func (r *Room) Boom() *api.Error { panic("boom") }
+
+func TestStash(t *testing.T) {
+	n := newTestNode(t)
+	order := make(chan string, 8)
+	pid := spawn(t, n, func() api.IActor { return &Room{order: order} })
+	for _, s := range []string{"m0", "m1"} {
+		s := s
+		_ = n.system.Send(nil, pid, "Chat", &s)
+	}
+	reply := make(chan string, 1)
+	go func() {
+		s, r := "call", ""
+		_ = n.system.Call(nil, pid, "Chat", &s, &r)
+		reply <- r
+	}()
+	time.Sleep(20 * time.Millisecond)
+	_ = n.system.Send(nil, pid, "Ready", 1)
+	after := "after"
+	_ = n.system.Send(nil, pid, "Chat", &after)
+	for i, want := range []string{"m0", "m1", "call", "after"} {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Fatalf("message %d = %s, want %s", i, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("stashed message not processed")
+		}
+	}
+	if r := <-reply; r != "call" {
+		t.Fatalf("stashed call reply = %s", r)
+	}
+}
+
+func TestStashPanic(t *testing.T) {
+	n := newTestNode(t)
+	pid := spawn(t, n, func() api.IActor { return &Room{order: make(chan string, 8)} })
+	done := make(chan *api.Error, 1)
+	go func() { done <- n.system.Call(nil, pid, "Boom", 1, nil) }()
+	time.Sleep(20 * time.Millisecond)
+	_ = n.system.Send(nil, pid, "Ready", 1)
+	// 崩溃的缓存调用立即收到错误,而不是等到超时
+	select {
+	case err := <-done:
+		if err != api.ErrActorPanic {
+			t.Fatalf("stashed call = %v", err)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("panicked stashed call not completed")
+	}
+}
@@ -0,0 +1,147 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: bounded_mailbox
+ * @Version: 1.0.0
+ * @Date: 2025/1/9 10:30
+ */
+
+package actor
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"time"
+)
+
+// boundedQueue
+// @Description: 固定容量的多生产者单消费者队列
+type boundedQueue struct {
+	ch chan interface{}
+}
+
+func newBoundedQueue(size int) *boundedQueue {
+	if size <= 0 {
+		size = 1
+	}
+	return &boundedQueue{ch: make(chan interface{}, size)}
+}
+
+// Push
+// @Description: 阻塞直到有空位,boundedMailbox投递只使用TryPush和PushTimeout
+// @receiver q
+// @param x
+func (q *boundedQueue) Push(x interface{}) {
+	q.ch <- x
+}
+
+func (q *boundedQueue) TryPush(x interface{}) bool {
+	select {
+	case q.ch <- x:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *boundedQueue) PushTimeout(x interface{}, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case q.ch <- x:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (q *boundedQueue) Pop() interface{} {
+	select {
+	case x := <-q.ch:
+		return x
+	default:
+		return nil
+	}
+}
+
+func (q *boundedQueue) Empty() bool {
+	return len(q.ch) == 0
+}
+
+var _ api.IActorMailbox = &boundedMailbox{}
+
+// NewBoundedMailbox
+// @Description: 有界邮箱
+// @param size 容量
+// @param policy 邮箱满时的处理策略
+// @param timeout MailboxOverflowBlock策略下投递者最长阻塞时间,不大于0时邮箱满直接拒绝
+func NewBoundedMailbox(size int, policy api.MailboxOverflowPolicy, timeout time.Duration) api.IActorMailbox {
+	q := newBoundedQueue(size)
+	m := &boundedMailbox{
+		mailbox: newMailbox(q),
+		bounded: q,
+		policy:  policy,
+		timeout: timeout,
+	}
+	return m
+}
+
+type boundedMailbox struct {
+	*mailbox
+	bounded *boundedQueue
+	policy  api.MailboxOverflowPolicy
+	timeout time.Duration
+}
+
+func (m *boundedMailbox) PostMessage(msg interface{}) *api.Error {
+	if msg == nil {
+		return nil
+	}
+	if err := m.push(msg); err != nil {
+		return err
+	}
+	return m.schedule()
+}
+
+func (m *boundedMailbox) push(msg interface{}) *api.Error {
+	m.inCnt.Add(1)
+	if m.bounded.TryPush(msg) {
+		return nil
+	}
+	switch m.policy {
+	case api.MailboxOverflowDropOldest:
+		for !m.bounded.TryPush(msg) {
+			if oldest := m.bounded.Pop(); oldest != nil {
+				m.drop(oldest, true)
+			}
+		}
+		return nil
+	case api.MailboxOverflowDropNewest:
+		m.drop(msg, true)
+		return nil
+	case api.MailboxOverflowBlock:
+		if m.timeout > 0 && m.bounded.PushTimeout(msg, m.timeout) {
+			return nil
+		}
+	}
+	m.drop(msg, false)
+	return api.ErrMailboxFull
+}
+
+// drop
+// @Description: 丢弃消息并记录死信,等待回复的调用方立即收到ErrMailboxFull
+// @receiver m
+// @param msg
+// @param respond 拒绝时错误直接返回给投递者,不需要回复
+func (m *boundedMailbox) drop(msg interface{}, respond bool) {
+	m.dropCnt.Add(1)
+	message, ok := msg.(*api.Message)
+	if !ok {
+		return
+	}
+	if ctx, ok := m.invoker.(api.IActorContext); ok && ctx.System() != nil {
+		ctx.System().DeadLetter().Publish(message, api.ErrMailboxFull)
+	}
+	if respond {
+		_ = message.Respond(&api.RespondMessage{Err: api.ErrMailboxFull})
+	}
+}
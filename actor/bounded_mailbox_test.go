@@ -0,0 +1,150 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: bounded_mailbox_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/9 10:30
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Blocker
+// @Description: Hold阻塞actor直到release关闭,用于填满邮箱
+type Blocker struct {
+	api.BuiltinActor
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *Blocker) Hold() *api.Error {
+	b.started <- struct{}{}
+	<-b.release
+	return nil
+}
+
+func (b *Blocker) Echo(v *int) (*int, *api.Error) { return v, nil }
+
+// Waiter
+// @Description: Hold中发起异步调用和定时器后阻塞,回调在邮箱满时投递
+type Waiter struct {
+	Blocker
+	echo  *api.Pid
+	fired chan string
+}
+
+func (w *Waiter) Hold() *api.Error {
+	v, reply := 1, 0
+	_ = w.Ctx.CallAsync(w.echo, "Echo", &v, &reply, func(_ interface{}, err *api.Error) {
+		w.fired <- "continuation"
+	})
+	w.Ctx.AfterFunc(time.Millisecond, nil, func(_ uint64, _ interface{}) {
+		w.fired <- "timer"
+	})
+	return w.Blocker.Hold()
+}
+
+func spawnBlocked(t *testing.T, n *testNode, mb api.IActorMailbox) (*api.Pid, chan struct{}) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	pid := spawn(t, n, func() api.IActor { return &Blocker{started: started, release: release} }, api.WithActorMailBox(mb))
+	_ = n.system.Send(nil, pid, "Hold", 1)
+	<-started
+	t.Cleanup(func() { close(release) })
+	return pid, release
+}
+
+func TestBoundedMailboxDrop(t *testing.T) {
+	n := newTestNode(t)
+	n.system.SetTimeout(time.Second)
+	for _, policy := range []api.MailboxOverflowPolicy{api.MailboxOverflowDropOldest, api.MailboxOverflowDropNewest} {
+		before := n.system.DeadLetter().CountByReason(api.ErrMailboxFull)
+		mb := NewBoundedMailbox(1, policy, 0)
+		pid, _ := spawnBlocked(t, n, mb)
+
+		v, reply := 1, 0
+		done := make(chan *api.Error, 1)
+		if policy == api.MailboxOverflowDropOldest {
+			// 排队中的调用被新消息挤掉
+			go func() { done <- n.system.Call(nil, pid, "Echo", &v, &reply) }()
+			for mb.Len() < 1 {
+				time.Sleep(time.Millisecond)
+			}
+			_ = n.system.Send(nil, pid, "Echo", &v)
+		} else {
+			_ = n.system.Send(nil, pid, "Echo", &v)
+			go func() { done <- n.system.Call(nil, pid, "Echo", &v, &reply) }()
+		}
+		select {
+		case err := <-done:
+			if err != api.ErrMailboxFull {
+				t.Fatalf("policy %d call = %v", policy, err)
+			}
+		case <-time.After(500 * time.Millisecond):
+			t.Fatalf("policy %d dropped call not completed", policy)
+		}
+		if n.system.DeadLetter().CountByReason(api.ErrMailboxFull) != before+1 {
+			t.Fatalf("policy %d dead letter not published", policy)
+		}
+	}
+}
+
+func TestBoundedMailboxReject(t *testing.T) {
+	n := newTestNode(t)
+	for _, policy := range []api.MailboxOverflowPolicy{api.MailboxOverflowReject, api.MailboxOverflowBlock} {
+		mb := NewBoundedMailbox(1, policy, 0)
+		pid, _ := spawnBlocked(t, n, mb)
+		v := 1
+		if err := n.system.Send(nil, pid, "Echo", &v); err != nil {
+			t.Fatal(err)
+		}
+		if err := n.system.Send(nil, pid, "Echo", &v); err != api.ErrMailboxFull {
+			t.Fatalf("policy %d send = %v", policy, err)
+		}
+	}
+
+	mb := NewBoundedMailbox(1, api.MailboxOverflowBlock, 200*time.Millisecond)
+	pid, release := spawnBlocked(t, n, mb)
+	v := 1
+	_ = n.system.Send(nil, pid, "Echo", &v)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release <- struct{}{}
+	}()
+	if err := n.system.Send(nil, pid, "Echo", &v); err != nil {
+		t.Fatalf("blocked send = %v", err)
+	}
+}
+
+func TestBoundedMailboxInternal(t *testing.T) {
+	n := newTestNode(t)
+	for _, policy := range []api.MailboxOverflowPolicy{api.MailboxOverflowReject, api.MailboxOverflowDropOldest, api.MailboxOverflowDropNewest} {
+		echo := spawn(t, n, func() api.IActor { return new(Blocker) })
+		started, release, fired := make(chan struct{}, 1), make(chan struct{}), make(chan string, 2)
+		pid := spawn(t, n, func() api.IActor {
+			return &Waiter{Blocker: Blocker{started: started, release: release}, echo: echo, fired: fired}
+		}, api.WithActorMailBox(NewBoundedMailbox(1, policy, 0)))
+		_ = n.system.Send(nil, pid, "Hold", 1)
+		<-started
+		v := 1
+		_ = n.system.Send(nil, pid, "Echo", &v)
+		_ = n.system.Send(nil, pid, "Echo", &v)
+		// 邮箱满时回调和定时器触发,不能被丢弃
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		got := make(map[string]bool)
+		for len(got) < 2 {
+			select {
+			case name := <-fired:
+				got[name] = true
+			case <-time.After(time.Second):
+				t.Fatalf("policy %d delivered %v", policy, got)
+			}
+		}
+	}
+}
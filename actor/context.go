@@ -9,10 +9,12 @@
 package actor
 
 import (
+	"context"
 	"github.com/dingqinghui/gas/api"
 	"github.com/dingqinghui/gas/extend/reflectx"
 	"github.com/dingqinghui/gas/zlog"
 	"go.uber.org/zap"
+	"time"
 )
 
 type baseActorContext struct {
@@ -25,14 +27,40 @@ type baseActorContext struct {
 	pid        *api.Pid
 	initParams interface{}
 	groups     map[string]struct{}
+	producer   api.ActorProducer
+	parent     *api.Pid
+	children   map[uint64]*api.Pid
+	childStats map[uint64]*api.RestartStatistics
+	supervisor api.ISupervisorStrategy
+	guardian   *guardian
+	watchers   map[string]*watchPair
+	watching   map[string]*api.Pid
+	stopped    bool
+	timers     map[uint64]*actorTimer
+	timerSeq   uint64
+	idle       receiveTimeout
+	behaviors  []*behavior
+	stash      []*api.Message
+	unstashed  []*api.Message
+	stashed    bool
+	inbound    api.InboundHandler
+	outbound   []api.OutboundMiddleware
+
+	remoteWatcher *remoteWatcher
 }
 
 var _ api.IActorContext = &baseActorContext{}
 var _ api.IActorMessageInvoker = &baseActorContext{}
+var _ api.ISupervisor = &baseActorContext{}
 
 func NewBaseActorContext() *baseActorContext {
 	ctx := new(baseActorContext)
 	ctx.groups = make(map[string]struct{})
+	ctx.children = make(map[uint64]*api.Pid)
+	ctx.childStats = make(map[uint64]*api.RestartStatistics)
+	ctx.watchers = make(map[string]*watchPair)
+	ctx.watching = make(map[string]*api.Pid)
+	ctx.timers = make(map[uint64]*actorTimer)
 	return ctx
 }
 
@@ -41,7 +69,14 @@ func (a *baseActorContext) Name() string {
 }
 
 func (a *baseActorContext) InvokerMessage(msg interface{}) *api.Error {
-	a.mbm = msg.(*api.Message)
+	err := a.invoke(msg.(*api.Message))
+	a.invokeUnstashed()
+	return err
+}
+
+func (a *baseActorContext) invoke(msg *api.Message) *api.Error {
+	a.mbm = msg
+	a.stashed = false
 	if err := a.invokerMessage(a.mbm); err != nil {
 		zlog.Error("actor处理消息失败",
 			zap.String("name", reflectx.TypeFullName(a.Actor())),
@@ -53,14 +88,51 @@ func (a *baseActorContext) InvokerMessage(msg interface{}) *api.Error {
 }
 
 func (a *baseActorContext) invokerMessage(msg *api.Message) *api.Error {
-
+	// 系统通道优先处理停止消息,停止后剩余消息直接拒绝
+	if a.stopped {
+		return a.rejectMessage(msg)
+	}
 	switch msg.Method {
 	case api.InitFuncName:
-		return a.Actor().OnInit(a)
+		if err := a.Actor().OnInit(a); err != nil {
+			return err
+		}
+		a.SetReceiveTimeout(a.idle.duration)
+		return nil
 	case api.StopFuncName:
-		return a.OnStop()
+		a.stopped = true
+		err := a.OnStop()
+		_ = msg.Respond(&api.RespondMessage{Err: err})
+		return err
+	case api.RestartFuncName:
+		return a.restart()
+	case api.FailureFuncName:
+		f, _ := msg.Body().(*failure)
+		return a.handleFailure(f)
+	case api.WatchFuncName:
+		return a.handleWatch(msg)
+	case api.UnwatchFuncName:
+		return a.handleUnwatch(msg)
+	case api.TerminatedFuncName:
+		return a.handleTerminated(msg)
+	case api.TimerFuncName:
+		return a.handleTimer(msg)
+	case api.ReceiveTimeoutFuncName:
+		return a.handleReceiveTimeout(msg)
+	case api.ContinuationFuncName:
+		if continuation, ok := msg.Body().(func()); ok {
+			continuation()
+		}
+		return nil
 	}
 
+	// 调用方已超时,不再执行
+	if msg.Expired() {
+		_ = msg.Respond(&api.RespondMessage{Err: api.ErrActorCallTimeout})
+		return api.ErrActorCallTimeout
+	}
+
+	a.resetReceiveTimeout()
 	switch msg.Typ {
 	case api.MessageEnumInner:
 		return a.invokerInnerMessage(msg)
@@ -70,35 +142,104 @@ func (a *baseActorContext) invokerMessage(msg *api.Message) *api.Error {
 	return nil
 }
 
+func (a *baseActorContext) rejectMessage(msg *api.Message) *api.Error {
+	if a.reactivate(msg) {
+		return nil
+	}
+	if msg.Method == api.WatchFuncName && api.ValidPid(msg.From) {
+		terminated := &api.Terminated{Who: watchedPid(a.serializer(), msg, a.Self()), Reason: api.TerminatedReasonStopped}
+		_ = a.System().Send(a.Self(), msg.From, api.TerminatedFuncName, terminated)
+	}
+	a.System().DeadLetter().Publish(msg, api.ErrActorStopped)
+	_ = msg.Respond(&api.RespondMessage{Err: api.ErrActorStopped})
+	return api.ErrActorStopped
+}
+
+// reactivate
+// @Description: 按名字寻址的消息在停止前进入邮箱,交给激活器重新激活,而不是回复actor已停止
+// @receiver a
+// @param msg
+// @return bool
+func (a *baseActorContext) reactivate(msg *api.Message) bool {
+	to := msg.To
+	if api.IsInternalMethod(msg.Method) || to.GetUniqId() != 0 || to.GetName() == "" {
+		return false
+	}
+	system, ok := a.System().(*System)
+	if !ok || system.getActivator() == nil {
+		return false
+	}
+	_ = system.deliver(to, msg)
+	return true
+}
+
 func (a *baseActorContext) invokerNetMessage(msg *api.Message) *api.Error {
-	if a.router == nil {
+	router := a.Router()
+	if router == nil {
 		return api.ErrActorRouterIsNil
 	}
-	md := a.router.Get(msg.Method)
+	md := router.Get(msg.Method)
 	if md == nil {
+		if forwarder := a.forwarder(); forwarder != nil {
+			return forwarder.Forward(a, msg)
+		}
+		a.System().DeadLetter().Publish(msg, api.ErrActorNotMethod)
 		return api.ErrActorNotMethod
 	}
 	msg.Session.SetContext(a)
-	method := &networkMethod{md}
-	return method.call(a, msg)
+	return a.invokeInbound(msg, md).Err
 }
 
 func (a *baseActorContext) invokerInnerMessage(msg *api.Message) *api.Error {
-	if a.router == nil {
+	router := a.Router()
+	if router == nil {
+		_ = msg.Respond(&api.RespondMessage{Err: api.ErrActorRouterIsNil})
 		return api.ErrActorRouterIsNil
 	}
-	md := a.router.Get(msg.Method)
+	md := router.Get(msg.Method)
 	if md == nil {
+		if forwarder := a.forwarder(); forwarder != nil {
+			return a.forward(forwarder, msg)
+		}
+		a.System().DeadLetter().Publish(msg, api.ErrActorNotMethod)
+		_ = msg.Respond(&api.RespondMessage{Err: api.ErrActorNotMethod})
 		return api.ErrActorNotMethod
 	}
-	method := &innerMethod{md}
-	rsq := method.call(a, msg)
+	rsq := a.invokeInbound(msg, md)
+	// 消息已缓存,重新处理时再回复
+	if a.stashed {
+		return nil
+	}
 	if !api.IsOk(rsq.Err) {
+		_ = msg.Respond(rsq)
 		return rsq.Err
 	}
 	return msg.Respond(rsq)
 }
 
+func (a *baseActorContext) forwarder() api.IForwarder {
+	receiver, _ := a.behavior()
+	forwarder, _ := receiver.(api.IForwarder)
+	return forwarder
+}
+
+// forward
+// @Description: 没有对应方法的消息交给代理actor转发,转发失败或缓存时由这里处理回复
+// @receiver a
+// @param forwarder
+// @param msg
+// @return *api.Error
+func (a *baseActorContext) forward(forwarder api.IForwarder, msg *api.Message) *api.Error {
+	err := forwarder.Forward(a, msg)
+	if a.stashed {
+		return nil
+	}
+	if err != nil {
+		_ = msg.Respond(&api.RespondMessage{Err: err})
+	}
+	return err
+}
+
 func (a *baseActorContext) Message() *api.Message {
 	return a.mbm
 }
@@ -111,6 +252,25 @@ func (a *baseActorContext) System() api.IActorSystem {
 	return a.system
 }
 
+// node
+// @Description: actor所在节点
+// @receiver a
+// @return api.INode
+func (a *baseActorContext) node() api.INode {
+	if a.system == nil {
+		return nil
+	}
+	return a.system.Node()
+}
+
+func (a *baseActorContext) serializer() api.ISerializer {
+	node := a.node()
+	if node == nil {
+		return nil
+	}
+	return node.Serializer()
+}
+
 func (a *baseActorContext) Actor() api.IActor {
 	return a.actor
 }
@@ -123,7 +283,20 @@ func (a *baseActorContext) InitParams() interface{} {
 	return a.initParams
 }
 
+// Router
+// @Description: 当前处理消息的路由,Become后为behavior的路由
+// @receiver a
+// @return api.IActorRouter
 func (a *baseActorContext) Router() api.IActorRouter {
+	_, router := a.behavior()
+	return router
+}
+
+// BaseRouter
+// @Description: actor类型的路由,不随Become变化,可以在其他协程读取
+// @receiver a
+// @return api.IActorRouter
+func (a *baseActorContext) BaseRouter() api.IActorRouter {
 	return a.router
 }
 
@@ -145,6 +318,57 @@ func (a *baseActorContext) Call(to *api.Pid, funcName string, request, reply int
 	return a.System().Call(a.Self(), to, funcName, request, reply)
 }
 
+func (a *baseActorContext) CallTimeout(to *api.Pid, timeout time.Duration, funcName string, request, reply interface{}) *api.Error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return a.CallContext(ctx, to, funcName, request, reply)
+}
+
+func (a *baseActorContext) CallContext(ctx context.Context, to *api.Pid, funcName string, request, reply interface{}) *api.Error {
+	return a.System().CallContext(ctx, a.Self(), to, funcName, request, reply)
+}
+
+func (a *baseActorContext) RequestFuture(to *api.Pid, funcName string, request interface{}) api.IFuture {
+	return a.System().RequestFuture(a.Self(), to, funcName, request)
+}
+
+// CallAsync
+// @Description: 异步调用,结果通过自己的邮箱回调,不阻塞actor
+// @receiver a
+// @param to
+// @param funcName
+// @param request
+// @param reply 回复反序列化目标
+// @param callback
+// @return *api.Error
+func (a *baseActorContext) CallAsync(to *api.Pid, funcName string, request, reply interface{}, callback api.AsyncCallback) *api.Error {
+	if callback == nil {
+		return api.ErrInvalidActorMessage
+	}
+	self := a.Self()
+	process := a.Process()
+	a.RequestFuture(to, funcName, request).OnComplete(func(rsp *api.RespondMessage) {
+		continuation := func() {
+			err := rsp.Err
+			if api.IsOk(err) {
+				err = unmarshalRsp(a.serializer(), rsp, reply)
+			}
+			callback(reply, err)
+		}
+		message := &api.Message{
+			Method: api.ContinuationFuncName,
+			From:   to,
+			To:     self,
+		}
+		message.SetBody(continuation)
+		if err := process.PostMessage(message); err != nil {
+			zlog.Error("actor call async continuation",
+				zap.Uint64("uniqId", self.GetUniqId()), zap.String("method", funcName), zap.Error(err))
+		}
+	})
+	return nil
+}
+
 //func (a *baseActorContext) Response(session *api.Session, s2c interface{}) *api.Error {
 //	return a.Push(session, session.Mid, s2c)
 //}
@@ -190,11 +414,17 @@ func (a *baseActorContext) BroadcastGroup(name string, msg interface{}) *api.Err
 }
 
 func (a *baseActorContext) OnStop() *api.Error {
+	a.cancelTimers()
+	a.cancelReceiveTimeout()
+	a.clearStash()
+	a.StopChildren(a.Children()...)
 	for event, _ := range a.groups {
 		a.RemoveGroup(event)
 	}
 	if a.name != "" {
 		_, _ = a.System().UnregisterName(a.name)
 	}
-	return a.Actor().OnStop()
+	err := a.Actor().OnStop()
+	a.notifyTerminated()
+	return err
 }
@@ -0,0 +1,93 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: deadletter
+ * @Version: 1.0.0
+ * @Date: 2025/1/15 11:25
+ */
+
+package actor
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"github.com/duke-git/lancet/v2/maputil"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+// deadLetterLogInterval 死信日志最小间隔,期间的死信只计数
+const deadLetterLogInterval = time.Second
+
+var _ api.IDeadLetter = &deadLetter{}
+
+func newDeadLetter(system api.IActorSystem) *deadLetter {
+	return &deadLetter{
+		system:  system,
+		reasons: maputil.NewConcurrentMap[uint16, *atomic.Uint64](10),
+	}
+}
+
+type deadLetter struct {
+	system     api.IActorSystem
+	total      atomic.Uint64
+	reasons    *maputil.ConcurrentMap[uint16, *atomic.Uint64]
+	lastLog    atomic.Int64
+	suppressed atomic.Uint64
+}
+
+// Publish
+// @Description: 记录死信,发布到死信组
+// @receiver d
+// @param message
+// @param reason
+func (d *deadLetter) Publish(message *api.Message, reason *api.Error) {
+	if message == nil || api.IsInternalMethod(message.Method) {
+		return
+	}
+	if reason == nil {
+		reason = api.ErrProcessNotExist
+	}
+	d.total.Add(1)
+	cnt, _ := d.reasons.GetOrSet(reason.Id, new(atomic.Uint64))
+	cnt.Add(1)
+	d.log(message, reason)
+	letter := &api.DeadLetter{
+		Message: message,
+		Reason:  reason,
+		Time:    time.Now().UnixMilli(),
+	}
+	_ = d.system.Group().Broadcast(api.DeadLetterFuncName, nil, letter)
+}
+
+func (d *deadLetter) Count() uint64 {
+	return d.total.Load()
+}
+
+func (d *deadLetter) CountByReason(reason *api.Error) uint64 {
+	if reason == nil {
+		return 0
+	}
+	cnt, ok := d.reasons.Get(reason.Id)
+	if !ok {
+		return 0
+	}
+	return cnt.Load()
+}
+
+func (d *deadLetter) log(message *api.Message, reason *api.Error) {
+	now := time.Now().UnixNano()
+	last := d.lastLog.Load()
+	if now-last < int64(deadLetterLogInterval) || !d.lastLog.CompareAndSwap(last, now) {
+		d.suppressed.Add(1)
+		return
+	}
+	zlog.Warn("actor dead letter",
+		zap.String("method", message.Method),
+		zap.Stringer("from", message.From),
+		zap.Stringer("to", message.To),
+		zap.Error(reason),
+		zap.Uint64("suppressed", d.suppressed.Swap(0)),
+		zap.Uint64("total", d.total.Load()))
+}
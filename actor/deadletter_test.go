@@ -0,0 +1,120 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: deadletter_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/15 15:10
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Collector
+// @Description: 订阅死信组
+type Collector struct {
+	api.BuiltinActor
+	letters chan *api.DeadLetter
+}
+
+func (c *Collector) OnInit(ctx api.IActorContext) *api.Error {
+	_ = c.BuiltinActor.OnInit(ctx)
+	ctx.AddGroup(api.DeadLetterFuncName)
+	return nil
+}
+
+func (c *Collector) OnDeadLetter(letter *api.DeadLetter) *api.Error {
+	c.letters <- letter
+	return nil
+}
+
+func TestDeadLetter(t *testing.T) {
+	n := newTestNode(t)
+	letters := make(chan *api.DeadLetter, 16)
+	spawn(t, n, func() api.IActor { return &Collector{letters: letters} })
+	time.Sleep(10 * time.Millisecond)
+	deadLetter := n.system.DeadLetter()
+
+	v := 1
+	missing := &api.Pid{NodeId: n.GetID(), Name: "missing"}
+	if err := n.system.Send(nil, missing, "Echo", &v); err != api.ErrProcessNotExist {
+		t.Fatalf("send to missing = %v", err)
+	}
+	select {
+	case letter := <-letters:
+		if letter.Reason.Id != api.ErrProcessNotExist.Id || letter.Message.Method != "Echo" || letter.Time == 0 {
+			t.Fatalf("letter = %+v", letter)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dead letter not delivered to the group")
+	}
+
+	// 已停止的actor
+	pid := spawn(t, n, func() api.IActor { return new(Echo) })
+	process := n.system.Find(pid)
+	_ = n.system.Kill(pid)
+	message := api.BuildInnerMessage(nil, pid, "Echo", nil)
+	if err := process.PostMessage(message); err != api.ErrActorStopped {
+		t.Fatalf("post to stopped = %v", err)
+	}
+
+	// 邮箱已满
+	mb := NewBoundedMailbox(1, api.MailboxOverflowReject, 0)
+	blocked, _ := spawnBlocked(t, n, mb)
+	_ = n.system.Send(nil, blocked, "Echo", &v)
+	if err := n.system.Send(nil, blocked, "Echo", &v); err != api.ErrMailboxFull {
+		t.Fatalf("send to full mailbox = %v", err)
+	}
+
+	// 内部消息不计入
+	deadLetter.Publish(&api.Message{Method: api.TimerFuncName}, api.ErrActorStopped)
+
+	if deadLetter.Count() != 3 {
+		t.Fatalf("count = %d", deadLetter.Count())
+	}
+	for _, reason := range []*api.Error{api.ErrProcessNotExist, api.ErrActorStopped, api.ErrMailboxFull} {
+		if deadLetter.CountByReason(reason) != 1 {
+			t.Fatalf("count %v = %d", reason, deadLetter.CountByReason(reason))
+		}
+	}
+	for _, reason := range []*api.Error{api.ErrActorStopped, api.ErrMailboxFull} {
+		select {
+		case letter := <-letters:
+			if letter.Reason.Id != reason.Id {
+				t.Fatalf("letter reason = %v, want %v", letter.Reason, reason)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("dead letter %v not delivered", reason)
+		}
+	}
+}
+
+func TestDeadLetterLog(t *testing.T) {
+	n := newTestNode(t)
+	d := n.system.DeadLetter().(*deadLetter)
+	message := &api.Message{Method: "Echo"}
+	d.Publish(message, api.ErrProcessNotExist)
+	if d.lastLog.Load() == 0 || d.suppressed.Load() != 0 {
+		t.Fatal("first dead letter not logged")
+	}
+	// 间隔内只计数
+	for i := 0; i < 5; i++ {
+		d.Publish(message, api.ErrMailboxFull)
+	}
+	if d.suppressed.Load() != 5 {
+		t.Fatalf("suppressed = %d", d.suppressed.Load())
+	}
+	d.lastLog.Store(time.Now().Add(-deadLetterLogInterval).UnixNano())
+	d.Publish(message, api.ErrActorStopped)
+	if d.suppressed.Load() != 0 {
+		t.Fatalf("suppressed after interval = %d", d.suppressed.Load())
+	}
+	if d.Count() != 7 {
+		t.Fatalf("count = %d", d.Count())
+	}
+}
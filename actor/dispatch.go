@@ -8,38 +8,53 @@ import (
 )
 
 // 协程调度器
-type goroutineDispatcher int
+type goroutineDispatcher struct {
+	node       api.INode
+	throughput int
+}
 
-func NewDefaultDispatcher(throughput int) api.IActorDispatcher {
-	return goroutineDispatcher(throughput)
+// NewDefaultDispatcher
+// @Description: 在节点协程池中处理消息
+// @param node
+// @param throughput
+func NewDefaultDispatcher(node api.INode, throughput int) api.IActorDispatcher {
+	return &goroutineDispatcher{node: node, throughput: throughput}
 }
-func (goroutineDispatcher) Schedule(fn func(), recoverFun func(err interface{})) *api.Error {
-	if api.GetNode() == nil {
+
+func (d *goroutineDispatcher) Schedule(fn func(), recoverFun func(err interface{})) *api.Error {
+	if d.node == nil {
 		return nil
 	}
-	api.GetNode().Submit(fn, recoverFun)
+	d.node.Submit(fn, recoverFun)
 	return nil
 }
 
-func (d goroutineDispatcher) Throughput() int {
-	return int(d)
+func (d *goroutineDispatcher) Throughput() int {
+	return d.throughput
 }
 
 // 同步调度器
-type synchronizedDispatcher int
+type synchronizedDispatcher struct {
+	node       api.INode
+	throughput int
+}
 
-func (synchronizedDispatcher) Schedule(fn func(), recoverFun func(err interface{})) *api.Error {
-	if api.GetNode() == nil {
+func (d *synchronizedDispatcher) Schedule(fn func(), recoverFun func(err interface{})) *api.Error {
+	if d.node == nil {
 		return nil
 	}
-	api.GetNode().Try(fn, recoverFun)
+	d.node.Try(fn, recoverFun)
 	return nil
 }
 
-func (d synchronizedDispatcher) Throughput() int {
-	return int(d)
+func (d *synchronizedDispatcher) Throughput() int {
+	return d.throughput
 }
 
-func NewSynchronizedDispatcher(throughput int) api.IActorDispatcher {
-	return synchronizedDispatcher(throughput)
+// NewSynchronizedDispatcher
+// @Description: 在投递消息的协程中同步处理消息
+// @param node
+// @param throughput
+func NewSynchronizedDispatcher(node api.INode, throughput int) api.IActorDispatcher {
+	return &synchronizedDispatcher{node: node, throughput: throughput}
 }
@@ -0,0 +1,107 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: future
+ * @Version: 1.0.0
+ * @Date: 2025/1/10 14:20
+ */
+
+package actor
+
+import (
+	"github.com/RussellLuo/timingwheel"
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/asynctime"
+	"sync"
+	"time"
+)
+
+var _ api.IFuture = &future{}
+
+// newFuture
+// @Description: timeout不大于0时截止时间已过,直接超时完成
+// @param timeout
+// @return *future
+func newFuture(timeout time.Duration) *future {
+	f := &future{
+		done: make(chan struct{}),
+	}
+	if timeout <= 0 {
+		f.complete(&api.RespondMessage{Err: api.ErrActorCallTimeout})
+		return f
+	}
+	timer := asynctime.AfterFunc(timeout, func() {
+		f.complete(&api.RespondMessage{Err: api.ErrActorCallTimeout})
+	})
+	// 定时器可能在赋值前触发
+	f.Lock()
+	f.timer = timer
+	f.Unlock()
+	return f
+}
+
+type future struct {
+	sync.Mutex
+	done      chan struct{}
+	completed bool
+	rsp       *api.RespondMessage
+	callbacks []func(rsp *api.RespondMessage)
+	timer     *timingwheel.Timer
+}
+
+// complete
+// @Description: 只有第一次完成生效
+// @receiver f
+// @param rsp
+func (f *future) complete(rsp *api.RespondMessage) {
+	if rsp == nil {
+		rsp = new(api.RespondMessage)
+	}
+	f.Lock()
+	if f.completed {
+		f.Unlock()
+		return
+	}
+	f.completed = true
+	f.rsp = rsp
+	callbacks := f.callbacks
+	f.callbacks = nil
+	timer := f.timer
+	f.Unlock()
+
+	if timer != nil {
+		timer.Stop()
+	}
+	close(f.done)
+	for _, callback := range callbacks {
+		callback(rsp)
+	}
+}
+
+func (f *future) isDone() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *future) Wait() *api.RespondMessage {
+	<-f.done
+	return f.rsp
+}
+
+func (f *future) OnComplete(callback func(rsp *api.RespondMessage)) {
+	if callback == nil {
+		return
+	}
+	f.Lock()
+	if !f.completed {
+		f.callbacks = append(f.callbacks, callback)
+		f.Unlock()
+		return
+	}
+	f.Unlock()
+	callback(f.rsp)
+}
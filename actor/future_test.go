@@ -0,0 +1,105 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: future_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/10 14:20
+ */
+
+package actor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Asker
+// @Description: 异步调用其他actor,回调在自己的邮箱中执行
+type Asker struct {
+	api.BuiltinActor
+	result chan int
+}
+
+func (a *Asker) Ask(target *api.Pid) *api.Error {
+	v, reply := 7, new(int)
+	return a.Ctx.CallAsync(target, "Echo", &v, reply, func(_ interface{}, err *api.Error) {
+		if err != nil {
+			a.result <- -1
+			return
+		}
+		a.result <- *reply
+	})
+}
+
+func TestRequestFuture(t *testing.T) {
+	n := newTestNode(t)
+	pid := spawn(t, n, func() api.IActor { return new(Echo) })
+	v := 3
+	if rsp := n.system.RequestFuture(nil, pid, "Echo", &v).Wait(); !api.IsOk(rsp.Err) {
+		t.Fatal(rsp.Err)
+	}
+	if rsp := n.system.RequestFuture(nil, pid, "Nope", &v).Wait(); rsp.Err != api.ErrActorNotMethod {
+		t.Fatalf("unknown method = %v", rsp.Err)
+	}
+
+	result := make(chan int, 1)
+	asker := spawn(t, n, func() api.IActor { return &Asker{result: result} })
+	_ = n.system.Send(nil, asker, "Ask", pid)
+	select {
+	case got := <-result:
+		if got != 7 {
+			t.Fatalf("async reply = %d", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("async callback not invoked")
+	}
+}
+
+func TestExpiredDeadline(t *testing.T) {
+	n := newTestNode(t)
+	pid := spawn(t, n, func() api.IActor { return new(Echo) })
+	v := 3
+	f := n.system.(*System).requestFuture(nil, pid, "Echo", &v, time.Now().Add(-time.Second))
+	select {
+	case <-f.done:
+		if f.rsp.Err != api.ErrActorCallTimeout {
+			t.Fatalf("expired future = %v", f.rsp.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expired future never completes")
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if err := n.system.CallContext(ctx, nil, pid, "Echo", &v, &v); err != api.ErrActorCallTimeout {
+		t.Fatalf("expired call = %v", err)
+	}
+}
+
+func TestCallDeadline(t *testing.T) {
+	n := newTestNode(t)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	order := make(chan string, 8)
+	pid := spawn(t, n, func() api.IActor {
+		return &Recorder{Blocker: Blocker{started: started, release: release}, order: order}
+	})
+	_ = n.system.Send(nil, pid, "Hold", 1)
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := n.system.CallContext(ctx, nil, pid, "Low", 1, nil); err != api.ErrActorCallTimeout {
+		t.Fatalf("call = %v", err)
+	}
+	// 时间轮可能提前触发,等截止时间真正过去
+	deadline, _ := ctx.Deadline()
+	time.Sleep(time.Until(deadline) + time.Millisecond)
+	close(release)
+	// 调用方已超时,排队的消息不再执行
+	_ = n.system.Send(nil, pid, "High", 1)
+	if got := <-order; got != "high" {
+		t.Fatalf("expired call executed: %s", got)
+	}
+}
@@ -41,25 +41,42 @@ func (m *Group) Remove(name string, pid *api.Pid) {
 }
 
 func (m *Group) Broadcast(name string, from *api.Pid, msg interface{}) *api.Error {
-	if api.GetNode() == nil {
+	node := m.system.Node()
+	if node == nil {
 		return nil
 	}
 	event, ok := m.dict.Get(name)
 	if !ok {
 		return nil
 	}
-	data, err := api.GetNode().Serializer().Marshal(msg)
+	data, err := node.Serializer().Marshal(msg)
 	if err != nil {
 		return api.ErrMarshal
 	}
 	event.Range(func(pid *api.Pid, process api.IProcess) bool {
 		message := api.BuildInnerMessage(from, pid, name, data)
-		_ = process.PostMessage(message)
+		_ = m.post(process, message)
 		return true
 	})
 	return nil
 }
 
+// post
+// @Description: 与Send一样经过发送拦截器
+// @receiver m
+// @param process
+// @param message
+// @return *api.Error
+func (m *Group) post(process api.IProcess, message *api.Message) *api.Error {
+	system, ok := m.system.(*System)
+	if !ok {
+		return process.PostMessage(message)
+	}
+	return system.outboundMessage(message, func(message *api.Message) *api.Error {
+		return process.PostMessage(message)
+	})
+}
+
 func (m *Group) Range(eventName string, f func(api.IProcess) bool) {
 	event, ok := m.dict.Get(eventName)
 	if !ok {
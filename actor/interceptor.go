@@ -0,0 +1,111 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: interceptor
+ * @Version: 1.0.0
+ * @Date: 2025/1/16 10:50
+ */
+
+package actor
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/reflectx"
+)
+
+// UseInbound
+// @Description: 系统消息处理拦截器,在Spawn之前设置,对之后创建的actor生效
+// @receiver s
+// @param middlewares
+func (s *System) UseInbound(middlewares ...api.InboundMiddleware) {
+	s.inbound = append(s.inbound, middlewares...)
+}
+
+// UseOutbound
+// @Description: 系统发送消息拦截器,在启动时设置
+// @receiver s
+// @param middlewares
+func (s *System) UseOutbound(middlewares ...api.OutboundMiddleware) {
+	s.outbound = append(s.outbound, middlewares...)
+}
+
+// outboundMessage
+// @Description: 本节点发出的用户消息经过发送者actor和系统的拦截器后投递,远程节点转发来的消息直接投递
+// @receiver s
+// @param message
+// @param deliver
+// @return *api.Error
+func (s *System) outboundMessage(message *api.Message, deliver api.OutboundHandler) *api.Error {
+	if message == nil || api.IsInternalMethod(message.Method) {
+		return deliver(message)
+	}
+	if message.From != nil && !s.IsLocalPid(message.From) {
+		return deliver(message)
+	}
+	middlewares := s.outbound
+	if sender := s.senderContext(message.From); sender != nil && len(sender.outbound) > 0 {
+		middlewares = make([]api.OutboundMiddleware, 0, len(sender.outbound)+len(s.outbound))
+		middlewares = append(middlewares, sender.outbound...)
+		middlewares = append(middlewares, s.outbound...)
+	}
+	if len(middlewares) == 0 {
+		return deliver(message)
+	}
+	return api.ChainOutbound(middlewares, deliver)(message)
+}
+
+func (s *System) senderContext(from *api.Pid) *baseActorContext {
+	if from == nil || from.GetUniqId() == 0 {
+		return nil
+	}
+	process := s.FindById(from.GetUniqId())
+	if process == nil {
+		return nil
+	}
+	ctx, _ := process.Context().(*baseActorContext)
+	return ctx
+}
+
+// inboundChain
+// @Description: 系统拦截器在外层,actor拦截器在内层
+// @param system
+// @param opt
+// @param final
+// @return api.InboundHandler
+func inboundChain(system *System, opt *api.ActorProcessOptions, final api.InboundHandler) api.InboundHandler {
+	if len(system.inbound) == 0 && len(opt.Inbound) == 0 {
+		return final
+	}
+	middlewares := make([]api.InboundMiddleware, 0, len(system.inbound)+len(opt.Inbound))
+	middlewares = append(middlewares, system.inbound...)
+	middlewares = append(middlewares, opt.Inbound...)
+	return api.ChainInbound(middlewares, final)
+}
+
+// invokeMethod
+// @Description: 调用当前处理对象的方法
+// @receiver a
+// @param _
+// @param msg
+// @param md
+// @return *api.RespondMessage
+func (a *baseActorContext) invokeMethod(_ api.IActorContext, msg *api.Message, md *reflectx.Method) *api.RespondMessage {
+	receiver, _ := a.behavior()
+	if msg.Typ == api.MessageEnumNetwork {
+		method := &networkMethod{md}
+		return &api.RespondMessage{Err: method.call(a.serializer(), receiver, msg)}
+	}
+	method := &innerMethod{md}
+	return method.call(a.serializer(), receiver, msg)
+}
+
+func (a *baseActorContext) invokeInbound(msg *api.Message, md *reflectx.Method) *api.RespondMessage {
+	if a.inbound == nil {
+		return a.invokeMethod(a, msg, md)
+	}
+	rsp := a.inbound(a, msg, md)
+	if rsp == nil {
+		rsp = new(api.RespondMessage)
+	}
+	return rsp
+}
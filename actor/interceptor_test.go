@@ -0,0 +1,81 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: interceptor_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/16 10:20
+ */
+
+package actor
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/reflectx"
+)
+
+// Member
+// @Description: 加入Event组
+type Member struct {
+	api.BuiltinActor
+	events chan int
+}
+
+func (m *Member) OnInit(ctx api.IActorContext) *api.Error {
+	_ = m.BuiltinActor.OnInit(ctx)
+	ctx.AddGroup("Event")
+	return nil
+}
+
+func (m *Member) Event(v *int) *api.Error {
+	m.events <- *v
+	return nil
+}
+
+func TestInterceptor(t *testing.T) {
+	n := newTestNode(t)
+	var outbound atomic.Int32
+	n.system.UseOutbound(func(next api.OutboundHandler) api.OutboundHandler {
+		return func(msg *api.Message) *api.Error {
+			outbound.Add(1)
+			return next(msg)
+		}
+	})
+	deny := func(next api.InboundHandler) api.InboundHandler {
+		return func(ctx api.IActorContext, msg *api.Message, md *reflectx.Method) *api.RespondMessage {
+			if msg.Method == "Boom" {
+				return &api.RespondMessage{Err: api.ErrActorNotMethod}
+			}
+			return next(ctx, msg, md)
+		}
+	}
+	pid := spawn(t, n, func() api.IActor { return new(Echo) }, api.WithActorInbound(deny))
+	v, reply := 5, 0
+	if err := n.system.Call(nil, pid, "Echo", &v, &reply); err != nil || reply != 5 {
+		t.Fatalf("call = %v %d", err, reply)
+	}
+	if err := n.system.Call(nil, pid, "Boom", &v, &reply); err != api.ErrActorNotMethod {
+		t.Fatalf("denied call = %v", err)
+	}
+	if got := outbound.Load(); got != 2 {
+		t.Fatalf("outbound calls = %d", got)
+	}
+
+	events := make(chan int, 1)
+	spawn(t, n, func() api.IActor { return &Member{events: events} })
+	time.Sleep(10 * time.Millisecond)
+	if err := n.system.Group().Broadcast("Event", nil, &v); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-events:
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	if got := outbound.Load(); got != 3 {
+		t.Fatalf("broadcast skipped outbound interceptors, calls = %d", got)
+	}
+}
@@ -22,59 +22,183 @@ const (
 
 var _ api.IActorMailbox = &mailbox{}
 
+type queue interface {
+	Push(x interface{})
+	Pop() interface{}
+	Empty() bool
+}
+
 type mailbox struct {
 	invoker       api.IActorMessageInvoker
-	queue         *mpsc.Queue
+	systemQueue   *mpsc.Queue
+	queue         queue
+	lanes         []queue
+	priorities    map[string]api.MessagePriority
 	dispatch      api.IActorDispatcher
 	dispatchStat  atomic.Int32
 	inCnt, outCnt atomic.Uint64
+	dropCnt       atomic.Uint64
+	current       interface{}
 }
 
 var _ api.IActorMailbox = &mailbox{}
 
 func NewMailbox() *mailbox {
+	return newMailbox(mpsc.NewQueue())
+}
+
+func newMailbox(q queue) *mailbox {
 	m := &mailbox{
-		queue: mpsc.NewQueue(),
+		systemQueue: mpsc.NewQueue(),
+		queue:       q,
 	}
 	return m
 }
 
+// NewPriorityMailbox
+// @Description: 按方法名区分优先级的邮箱,高优先级消息先处理,未配置的方法为PriorityNormal
+// @param priorities
+func NewPriorityMailbox(priorities map[string]api.MessagePriority) api.IActorMailbox {
+	m := NewMailbox()
+	m.priorities = priorities
+	m.lanes = make([]queue, api.PriorityHigh+1)
+	for i := range m.lanes {
+		m.lanes[i] = mpsc.NewQueue()
+	}
+	m.lanes[api.PriorityNormal] = m.queue
+	return m
+}
+
 func (m *mailbox) RegisterHandlers(invoker api.IActorMessageInvoker, dispatcher api.IActorDispatcher) {
 	m.invoker = invoker
 	m.dispatch = dispatcher
 }
 
+// Len
+// @Description: 邮箱中待处理的消息数量
+// @receiver m
+// @return int
+func (m *mailbox) Len() int {
+	in := m.inCnt.Load()
+	out := m.outCnt.Load() + m.dropCnt.Load()
+	if out >= in {
+		return 0
+	}
+	return int(in - out)
+}
+
 func (m *mailbox) PostMessage(msg interface{}) *api.Error {
 	if msg == nil {
 		return nil
 	}
-	m.queue.Push(msg)
+	m.userQueue(msg).Push(msg)
 	m.inCnt.Add(1)
 	return m.schedule()
 }
 
+// PostSystemMessage
+// @Description: 系统消息不受容量限制,优先处理
+// @receiver m
+// @param msg
+// @return *api.Error
+func (m *mailbox) PostSystemMessage(msg interface{}) *api.Error {
+	if msg == nil {
+		return nil
+	}
+	m.systemQueue.Push(msg)
+	m.inCnt.Add(1)
+	return m.schedule()
+}
+
+func (m *mailbox) userQueue(msg interface{}) queue {
+	if m.lanes == nil {
+		return m.queue
+	}
+	message, ok := msg.(*api.Message)
+	if !ok {
+		return m.queue
+	}
+	priority, ok := m.priorities[message.Method]
+	if !ok || priority < api.PriorityLow || priority > api.PriorityHigh {
+		return m.queue
+	}
+	return m.lanes[priority]
+}
+
+func (m *mailbox) popUser() interface{} {
+	if m.lanes == nil {
+		return m.queue.Pop()
+	}
+	for i := len(m.lanes) - 1; i >= 0; i-- {
+		if msg := m.lanes[i].Pop(); msg != nil {
+			return msg
+		}
+	}
+	return nil
+}
+
+func (m *mailbox) empty() bool {
+	if !m.systemQueue.Empty() {
+		return false
+	}
+	if m.lanes == nil {
+		return m.queue.Empty()
+	}
+	for _, lane := range m.lanes {
+		if !lane.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *mailbox) schedule() *api.Error {
 	if !m.dispatchStat.CompareAndSwap(idle, running) {
 		return nil
 	}
-	if err := m.dispatch.Schedule(m.process, func(err interface{}) {
-		//_ = m.invoker.InvokerMessage(NewMailBoxMessage(PanicFuncName, nil, err))
-	}); err != nil {
+	if err := m.dispatch.Schedule(m.process, m.recover); err != nil {
 		return err
 	}
 	return nil
 }
 
+// recover
+// @Description: 处理消息崩溃,通知监督者并继续调度剩余消息
+// @receiver m
+// @param reason
+func (m *mailbox) recover(reason interface{}) {
+	var msg interface{} = m.current
+	m.current = nil
+	// 处理缓存的消息时崩溃,崩溃的是正在处理的缓存消息
+	if ctx, ok := m.invoker.(api.IActorContext); ok && ctx.Message() != nil {
+		msg = ctx.Message()
+	}
+	m.invoker.EscalateFailure(reason, msg)
+	m.dispatchStat.Store(idle)
+	if m.Len() > 0 {
+		_ = m.schedule()
+	}
+}
+
 func (m *mailbox) process() {
 	m.run()
 	m.dispatchStat.CompareAndSwap(running, idle)
+	// 设置空闲前可能有新消息投递,空闲后队列可能已被新的调度消费,只能通过计数判断
+	if m.Len() > 0 {
+		_ = m.schedule()
+	}
 }
 
 func (m *mailbox) run() {
 	throughput := m.dispatch.Throughput()
 	var i int
 	for true {
-		if m.queue.Empty() {
+		// 系统消息优先
+		if msg := m.systemQueue.Pop(); msg != nil {
+			m.invoke(msg)
+			continue
+		}
+		if m.empty() {
 			return
 		}
 		if i > throughput {
@@ -83,23 +207,29 @@ func (m *mailbox) run() {
 			continue
 		}
 		i++
-		msg := m.queue.Pop()
+		msg := m.popUser()
 		if msg != nil {
-			_ = m.invokerMessage(msg)
+			m.invoke(msg)
 		} else {
 			return
 		}
 	}
 }
 
+func (m *mailbox) invoke(msg interface{}) {
+	m.current = msg
+	_ = m.invokerMessage(msg)
+	m.current = nil
+}
+
 // invokerMessage
 // @Description: 从队列中读取消息，并调用invoker处理
 // @receiver m
 // @return error
 func (m *mailbox) invokerMessage(msg interface{}) error {
+	m.outCnt.Add(1)
 	if err := m.invoker.InvokerMessage(msg); err != nil {
 		return err
 	}
-	m.outCnt.Add(1)
 	return nil
 }
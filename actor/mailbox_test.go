@@ -0,0 +1,70 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: mailbox_test
+ * @Version: 1.0.0
+ * @Date: 2024/10/15 14:27
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Recorder
+// @Description: 记录处理顺序
+type Recorder struct {
+	Blocker
+	order chan string
+}
+
+func (r *Recorder) Low() *api.Error  { r.order <- "low"; return nil }
+func (r *Recorder) High() *api.Error { r.order <- "high"; return nil }
+
+func TestPriorityMailbox(t *testing.T) {
+	n := newTestNode(t)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	order := make(chan string, 8)
+	priorities := map[string]api.MessagePriority{"Low": api.PriorityLow, "High": api.PriorityHigh}
+	pid := spawn(t, n, func() api.IActor {
+		return &Recorder{Blocker: Blocker{started: started, release: release}, order: order}
+	}, api.WithActorPriorities(priorities))
+	_ = n.system.Send(nil, pid, "Hold", 1)
+	<-started
+	for i := 0; i < 3; i++ {
+		_ = n.system.Send(nil, pid, "Low", 1)
+	}
+	_ = n.system.Send(nil, pid, "High", 1)
+	close(release)
+	for i, want := range []string{"high", "low", "low", "low"} {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Fatalf("message %d = %s, want %s", i, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("message not processed")
+		}
+	}
+}
+
+func TestSystemLane(t *testing.T) {
+	n := newTestNode(t)
+	pid := spawn(t, n, func() api.IActor { return new(Echo) })
+	ms := 10
+	for i := 0; i < 100; i++ {
+		_ = n.system.Send(nil, pid, "Sleep", &ms)
+	}
+	// 停止消息不排在用户消息之后
+	start := time.Now()
+	if err := n.system.Kill(pid); err != nil {
+		t.Fatal(err)
+	}
+	if cost := time.Since(start); cost > 200*time.Millisecond {
+		t.Fatalf("kill waited for user messages: %v", cost)
+	}
+}
@@ -20,14 +20,17 @@ func loadOptions(options ...api.ProcessOption) *api.ActorProcessOptions {
 	return opts
 }
 
-func getDispatcher(b *api.ActorProcessOptions) api.IActorDispatcher {
+func getDispatcher(node api.INode, b *api.ActorProcessOptions) api.IActorDispatcher {
 	if b.Dispatcher == nil {
-		b.Dispatcher = NewDefaultDispatcher(50)
+		b.Dispatcher = NewDefaultDispatcher(node, 50)
 	}
 	return b.Dispatcher
 }
 
 func getMailBox(b *api.ActorProcessOptions) api.IActorMailbox {
+	if b.Mailbox == nil && len(b.Priorities) > 0 {
+		b.Mailbox = NewPriorityMailbox(b.Priorities)
+	}
 	if b.Mailbox == nil {
 		b.Mailbox = NewMailbox()
 	}
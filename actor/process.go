@@ -10,6 +10,7 @@ package actor
 
 import (
 	"github.com/dingqinghui/gas/api"
+	"time"
 )
 
 func NewBaseProcess(ctx api.IActorContext, mailbox api.IActorMailbox) api.IProcess {
@@ -44,23 +45,42 @@ func (p *ProcessActor) valid() *api.Error {
 
 func (p *ProcessActor) PostMessage(message *api.Message) *api.Error {
 	if err := p.valid(); err != nil {
+		p.deadLetter(message, err)
 		return err
 	}
+	return p.post(message)
+}
+
+func (p *ProcessActor) post(message *api.Message) *api.Error {
+	// 定时器、异步回调和空闲超时丢弃后无法恢复,和系统消息一起走系统通道
+	if api.IsSystemLaneMethod(message.Method) {
+		return p.mailbox.PostSystemMessage(message)
+	}
 	return p.mailbox.PostMessage(message)
 }
 
 func (p *ProcessActor) PostMessageAndWait(message *api.Message) (rsp *api.RespondMessage) {
 	rsp = new(api.RespondMessage)
 	if err := p.valid(); err != nil {
+		p.deadLetter(message, err)
 		rsp.Err = err
 		return
 	}
-	waiter := newChanWaiter(p.ctx.System().Timeout())
+	return p.postAndWait(message)
+}
+
+func (p *ProcessActor) postAndWait(message *api.Message) (rsp *api.RespondMessage) {
+	rsp = new(api.RespondMessage)
+	timeout := p.ctx.System().Timeout()
+	if deadline := message.Deadline(); !deadline.IsZero() {
+		timeout = time.Until(deadline)
+	}
+	waiter := newChanWaiter(timeout)
 	message.SetRespond(func(rsp *api.RespondMessage) *api.Error {
 		waiter.Done(rsp)
 		return nil
 	})
-	if err := p.PostMessage(message); err != nil {
+	if err := p.post(message); err != nil {
 		rsp.Err = err
 		return
 	}
@@ -80,16 +100,31 @@ func (p *ProcessActor) Stop() *api.Error {
 	if err := p.BuiltinStopper.Stop(); err != nil {
 		return err
 	}
+	if p.mailbox == nil {
+		return api.ErrMailBoxNil
+	}
 	message := &api.Message{
 		Method: api.StopFuncName,
 	}
-	rsp := p.PostMessageAndWait(message)
+	// 已标记停止,直接投递到邮箱
+	rsp := p.postAndWait(message)
 	if !api.IsOk(rsp.Err) {
 		return rsp.Err
 	}
 	return nil
 }
 
+func (p *ProcessActor) deadLetter(message *api.Message, reason *api.Error) {
+	if p.ctx.System() == nil {
+		return
+	}
+	p.ctx.System().DeadLetter().Publish(message, reason)
+}
+
 func (p *ProcessActor) Context() api.IActorContext {
 	return p.ctx
 }
+
+func (p *ProcessActor) Mailbox() api.IActorMailbox {
+	return p.mailbox
+}
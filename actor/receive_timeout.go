@@ -0,0 +1,113 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: receive_timeout
+ * @Version: 1.0.0
+ * @Date: 2025/1/14 10:20
+ */
+
+package actor
+
+import (
+	"github.com/RussellLuo/timingwheel"
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/asynctime"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+	"time"
+)
+
+// receiveTimeout
+// @Description: 空闲检测,只在定时器触发时比较最后收到消息的时间,避免每条消息重置定时器
+type receiveTimeout struct {
+	duration    time.Duration
+	autoStop    bool
+	lastReceive time.Time
+	generation  uint64
+	timer       *timingwheel.Timer
+}
+
+func (a *baseActorContext) ReceiveTimeout() time.Duration {
+	return a.idle.duration
+}
+
+// SetReceiveTimeout
+// @Description: 设置空闲超时,d<=0关闭
+// @receiver a
+// @param d
+func (a *baseActorContext) SetReceiveTimeout(d time.Duration) {
+	a.cancelReceiveTimeout()
+	a.idle.duration = d
+	if d <= 0 {
+		return
+	}
+	a.idle.lastReceive = time.Now()
+	a.scheduleReceiveTimeout(d)
+}
+
+func (a *baseActorContext) scheduleReceiveTimeout(d time.Duration) {
+	generation := a.idle.generation
+	process := a.Process()
+	self := a.Self()
+	a.idle.timer = asynctime.AfterFunc(d, func() {
+		message := &api.Message{
+			Method: api.ReceiveTimeoutFuncName,
+			From:   self,
+			To:     self,
+		}
+		message.SetBody(generation)
+		_ = process.PostMessage(message)
+	})
+}
+
+func (a *baseActorContext) cancelReceiveTimeout() {
+	a.idle.generation++
+	if a.idle.timer != nil {
+		a.idle.timer.Stop()
+		a.idle.timer = nil
+	}
+}
+
+func (a *baseActorContext) resetReceiveTimeout() {
+	if a.idle.duration > 0 {
+		a.idle.lastReceive = time.Now()
+	}
+}
+
+// handleReceiveTimeout
+// @Description: 超时通知actor的OnReceiveTimeout,配置了自动停止则停止actor
+// @receiver a
+// @param msg
+// @return *api.Error
+func (a *baseActorContext) handleReceiveTimeout(msg *api.Message) *api.Error {
+	generation, ok := msg.Body().(uint64)
+	if !ok || generation != a.idle.generation || a.idle.duration <= 0 {
+		return nil
+	}
+	a.idle.timer = nil
+	if elapsed := time.Since(a.idle.lastReceive); elapsed < a.idle.duration {
+		a.scheduleReceiveTimeout(a.idle.duration - elapsed)
+		return nil
+	}
+	var err *api.Error
+	if router := a.Router(); router != nil && router.Get(api.ReceiveTimeoutFuncName) != nil {
+		err = a.invokerInnerMessage(msg)
+	}
+	if !a.idle.autoStop {
+		a.idle.lastReceive = time.Now()
+		a.scheduleReceiveTimeout(a.idle.duration)
+		return err
+	}
+	a.cancelReceiveTimeout()
+	node := a.node()
+	if node == nil {
+		return err
+	}
+	self := a.Self()
+	zlog.Info("actor receive timeout stop", zap.Uint64("uniqId", self.GetUniqId()), zap.String("name", a.Name()))
+	// 在actor自己的协程中,需异步等待actor停止
+	node.Submit(func() {
+		_ = a.System().Kill(self)
+	}, nil)
+	return err
+}
@@ -0,0 +1,117 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: receive_timeout_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/14 15:30
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Idler
+// @Description: 空闲超时写入timeouts
+type Idler struct {
+	api.BuiltinActor
+	timeouts chan time.Time
+}
+
+func (i *Idler) OnReceiveTimeout() *api.Error {
+	i.timeouts <- time.Now()
+	return nil
+}
+
+func (i *Idler) Ping() *api.Error { return nil }
+
+// Tick
+// @Description: 定时器消息不重置空闲时间
+func (i *Idler) Tick(ms *int) *api.Error {
+	i.Ctx.Every(time.Duration(*ms)*time.Millisecond, nil, func(_ uint64, _ interface{}) {})
+	return nil
+}
+
+func (i *Idler) Disable() *api.Error {
+	i.Ctx.SetReceiveTimeout(0)
+	return nil
+}
+
+func spawnIdler(t *testing.T, n *testNode, d time.Duration, autoStop bool) (*api.Pid, chan time.Time) {
+	timeouts := make(chan time.Time, 16)
+	pid := spawn(t, n, func() api.IActor { return &Idler{timeouts: timeouts} }, api.WithActorReceiveTimeout(d, autoStop))
+	return pid, timeouts
+}
+
+func expectTimeout(t *testing.T, timeouts chan time.Time, after time.Time, min time.Duration) {
+	t.Helper()
+	select {
+	case at := <-timeouts:
+		if elapsed := at.Sub(after); elapsed < min {
+			t.Fatalf("timeout after %v, want at least %v", elapsed, min)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("receive timeout not fired")
+	}
+}
+
+func TestReceiveTimeout(t *testing.T) {
+	n := newTestNode(t)
+	start := time.Now()
+	_, timeouts := spawnIdler(t, n, 50*time.Millisecond, false)
+	expectTimeout(t, timeouts, start, 40*time.Millisecond)
+	// 没有自动停止时继续检测
+	expectTimeout(t, timeouts, time.Now(), 30*time.Millisecond)
+}
+
+func TestReceiveTimeoutReset(t *testing.T) {
+	n := newTestNode(t)
+	pid, timeouts := spawnIdler(t, n, 50*time.Millisecond, false)
+	// 用户消息重置空闲时间
+	for i := 0; i < 8; i++ {
+		_ = n.system.Send(nil, pid, "Ping", 1)
+		time.Sleep(20 * time.Millisecond)
+	}
+	if len(timeouts) != 0 {
+		t.Fatal("receive timeout fired while receiving messages")
+	}
+	expectTimeout(t, timeouts, time.Now(), 20*time.Millisecond)
+
+	// 定时器等内部消息不重置
+	ms := 10
+	start := time.Now()
+	_ = n.system.Send(nil, pid, "Tick", &ms)
+	expectTimeout(t, timeouts, start, 30*time.Millisecond)
+}
+
+func TestReceiveTimeoutDisable(t *testing.T) {
+	n := newTestNode(t)
+	pid, timeouts := spawnIdler(t, n, 50*time.Millisecond, false)
+	if err := n.system.Call(nil, pid, "Disable", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-timeouts:
+		t.Fatal("disabled receive timeout fired")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestReceiveTimeoutStop(t *testing.T) {
+	n := newTestNode(t)
+	start := time.Now()
+	pid, timeouts := spawnIdler(t, n, 30*time.Millisecond, true)
+	expectTimeout(t, timeouts, start, 20*time.Millisecond)
+	// 通过node.Submit异步停止
+	deadline := time.Now().Add(time.Second)
+	for n.system.Find(pid) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("idle actor not stopped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
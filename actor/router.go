@@ -19,9 +19,9 @@ const fixedNetworkArgNum = 3
 
 var typeOfBytes = reflect.TypeOf(([]byte)(nil))
 
-func NewRouter(name string, actor api.IActor) *Router {
+func NewRouter(name string, receiver interface{}) *Router {
 	r := new(Router)
-	r.dict = reflectx.SuitableMethods(actor)
+	r.dict = reflectx.SuitableMethods(receiver)
 	r.name = name
 	return r
 }
@@ -40,11 +40,10 @@ func (h *Router) Set(name string, method *reflectx.Method) {
 	h.dict[name] = method
 }
 
-func newArg(argType reflect.Type, data []byte) (arg any, err *api.Error) {
-	if api.GetNode() == nil {
+func newArg(serializer api.ISerializer, argType reflect.Type, data []byte) (arg any, err *api.Error) {
+	if serializer == nil {
 		return
 	}
-	serializer := api.GetNode().Serializer()
 	if argType == typeOfBytes {
 		arg = data
 		return
@@ -64,14 +63,18 @@ type networkMethod struct {
 	*reflectx.Method
 }
 
-func (m *networkMethod) call(ctx api.IActorContext, msg *api.Message) *api.Error {
+func (m *networkMethod) call(serializer api.ISerializer, receiver interface{}, msg *api.Message) *api.Error {
+	if m.Invoke != nil {
+		_, err := m.Invoke(receiver, m.Name, msg.Session, msg.Data)
+		return invokeErr(err)
+	}
 	if len(m.ArgTypes) != fixedNetworkArgNum {
 		return api.ErrActorMethodArgNum
 	}
 	argValues := make([]reflect.Value, fixedNetworkArgNum, fixedNetworkArgNum)
-	argValues[0] = reflect.ValueOf(ctx.Actor())
+	argValues[0] = reflect.ValueOf(receiver)
 	argValues[1] = reflect.ValueOf(msg.Session)
-	request, err := newArg(m.ArgTypes[2], msg.Data)
+	request, err := newArg(serializer, m.ArgTypes[2], msg.Data)
 	if err != nil {
 		return err
 	}
@@ -91,16 +94,20 @@ type innerMethod struct {
 	*reflectx.Method
 }
 
-func (m *innerMethod) call(ctx api.IActorContext, msg *api.Message) (rsp *api.RespondMessage) {
+func (m *innerMethod) call(serializer api.ISerializer, receiver interface{}, msg *api.Message) (rsp *api.RespondMessage) {
 	rsp = new(api.RespondMessage)
+	if m.Invoke != nil {
+		m.invoke(serializer, receiver, msg, rsp)
+		return
+	}
 	if m.ArgNum < fixedInnerArgNum {
 		rsp.Err = api.ErrActorArgsNum
 		return
 	}
 	argValues := make([]reflect.Value, m.ArgNum, m.ArgNum)
-	argValues[0] = reflect.ValueOf(ctx.Actor())
+	argValues[0] = reflect.ValueOf(receiver)
 	if m.ArgNum >= fixedInnerArgNum+1 {
-		request, err := newArg(m.ArgTypes[1], msg.Data)
+		request, err := newArg(serializer, m.ArgTypes[1], msg.Data)
 		if err != nil {
 			rsp.Err = err
 			return
@@ -108,12 +115,12 @@ func (m *innerMethod) call(ctx api.IActorContext, msg *api.Message) (rsp *api.Re
 		argValues[1] = reflect.ValueOf(request)
 	}
 	values := m.Fun.Call(argValues)
-	m.returnValues(ctx, values, rsp)
+	m.returnValues(serializer, values, rsp)
 	return
 }
 
-func (m *innerMethod) returnValues(ctx api.IActorContext, values []reflect.Value, rsp *api.RespondMessage) {
-	if values == nil || api.GetNode() == nil {
+func (m *innerMethod) returnValues(serializer api.ISerializer, values []reflect.Value, rsp *api.RespondMessage) {
+	if values == nil || serializer == nil {
 		return
 	}
 	returnCnt := len(values)
@@ -127,7 +134,6 @@ func (m *innerMethod) returnValues(ctx api.IActorContext, values []reflect.Value
 		}
 	case 2:
 		if !values[0].IsNil() {
-			serializer := api.GetNode().Serializer()
 			if buf, err := serializer.Marshal(values[0].Interface()); err != nil {
 				rsp.Err = api.ErrMarshal
 				return
@@ -143,3 +149,34 @@ func (m *innerMethod) returnValues(ctx api.IActorContext, values []reflect.Value
 		}
 	}
 }
+
+// invoke
+// @Description: 调用代码生成的分发函数
+// @receiver m
+// @param serializer
+// @param receiver
+// @param msg
+// @param rsp
+func (m *innerMethod) invoke(serializer api.ISerializer, receiver interface{}, msg *api.Message, rsp *api.RespondMessage) {
+	reply, err := m.Invoke(receiver, m.Name, nil, msg.Data)
+	rsp.Err = invokeErr(err)
+	if reply == nil || serializer == nil {
+		return
+	}
+	buf, e := serializer.Marshal(reply)
+	if e != nil {
+		rsp.Err = api.ErrMarshal
+		return
+	}
+	rsp.Data = buf
+}
+
+func invokeErr(err error) *api.Error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*api.Error); ok {
+		return e
+	}
+	return api.ErrActorInvoke
+}
@@ -0,0 +1,159 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: strategy
+ * @Version: 1.0.0
+ * @Date: 2025/1/6 10:40
+ */
+
+package actor
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/asynctime"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+	"math/rand"
+	"time"
+)
+
+var defaultSupervisorStrategy = NewOneForOneStrategy(10, 10*time.Second, api.DefaultDecider)
+
+func DefaultSupervisorStrategy() api.ISupervisorStrategy {
+	return defaultSupervisorStrategy
+}
+
+// NewOneForOneStrategy
+// @Description: 只处理崩溃的actor
+// @param maxNrOfRetries withinDuration时间内最大重启次数,超过后停止actor
+// @param withinDuration
+// @param decider
+func NewOneForOneStrategy(maxNrOfRetries int, withinDuration time.Duration, decider api.DeciderFunc) api.ISupervisorStrategy {
+	if decider == nil {
+		decider = api.DefaultDecider
+	}
+	return &oneForOneStrategy{
+		maxNrOfRetries: maxNrOfRetries,
+		withinDuration: withinDuration,
+		decider:        decider,
+	}
+}
+
+type oneForOneStrategy struct {
+	maxNrOfRetries int
+	withinDuration time.Duration
+	decider        api.DeciderFunc
+}
+
+func (s *oneForOneStrategy) HandleFailure(supervisor api.ISupervisor, child *api.Pid, rs *api.RestartStatistics, reason interface{}, message interface{}) {
+	directive := s.decider(reason)
+	logFailure(child, reason, directive)
+	switch directive {
+	case api.ResumeDirective:
+		supervisor.ResumeChildren(child)
+	case api.RestartDirective:
+		if shouldStop(rs, s.maxNrOfRetries, s.withinDuration) {
+			supervisor.StopChildren(child)
+		} else {
+			supervisor.RestartChildren(child)
+		}
+	case api.StopDirective:
+		supervisor.StopChildren(child)
+	case api.EscalateDirective:
+		supervisor.EscalateFailure(reason, message)
+	}
+}
+
+// NewAllForOneStrategy
+// @Description: 一个子actor崩溃,所有子actor执行相同处理
+func NewAllForOneStrategy(maxNrOfRetries int, withinDuration time.Duration, decider api.DeciderFunc) api.ISupervisorStrategy {
+	if decider == nil {
+		decider = api.DefaultDecider
+	}
+	return &allForOneStrategy{
+		maxNrOfRetries: maxNrOfRetries,
+		withinDuration: withinDuration,
+		decider:        decider,
+	}
+}
+
+type allForOneStrategy struct {
+	maxNrOfRetries int
+	withinDuration time.Duration
+	decider        api.DeciderFunc
+}
+
+func (s *allForOneStrategy) HandleFailure(supervisor api.ISupervisor, child *api.Pid, rs *api.RestartStatistics, reason interface{}, message interface{}) {
+	directive := s.decider(reason)
+	logFailure(child, reason, directive)
+	switch directive {
+	case api.ResumeDirective:
+		supervisor.ResumeChildren(child)
+	case api.RestartDirective:
+		children := supervisor.Children()
+		if shouldStop(rs, s.maxNrOfRetries, s.withinDuration) {
+			supervisor.StopChildren(children...)
+		} else {
+			supervisor.RestartChildren(children...)
+		}
+	case api.StopDirective:
+		supervisor.StopChildren(supervisor.Children()...)
+	case api.EscalateDirective:
+		supervisor.EscalateFailure(reason, message)
+	}
+}
+
+// NewExponentialBackoffStrategy
+// @Description: 重启前等待一段时间,等待时间随崩溃次数递增,超过backoffWindow未崩溃则重置
+// @param backoffWindow
+// @param initialBackoff
+// @param maxNrOfRetries backoffWindow时间内最大重启次数,超过后交给上一级监督者
+func NewExponentialBackoffStrategy(backoffWindow, initialBackoff time.Duration, maxNrOfRetries int) api.ISupervisorStrategy {
+	return &exponentialBackoffStrategy{
+		backoffWindow:  backoffWindow,
+		initialBackoff: initialBackoff,
+		maxNrOfRetries: maxNrOfRetries,
+	}
+}
+
+type exponentialBackoffStrategy struct {
+	backoffWindow  time.Duration
+	initialBackoff time.Duration
+	maxNrOfRetries int
+}
+
+func (s *exponentialBackoffStrategy) HandleFailure(supervisor api.ISupervisor, child *api.Pid, rs *api.RestartStatistics, reason interface{}, message interface{}) {
+	if rs.NumberOfFailures(s.backoffWindow) == 0 {
+		rs.Reset()
+	}
+	rs.Fail()
+	if rs.FailureCount() > s.maxNrOfRetries {
+		rs.Reset()
+		logFailure(child, reason, api.EscalateDirective)
+		supervisor.EscalateFailure(reason, message)
+		return
+	}
+	backoff := int64(rs.FailureCount()) * int64(s.initialBackoff)
+	noise := rand.Int63n(int64(s.initialBackoff) + 1)
+	dur := time.Duration(backoff + noise)
+	logFailure(child, reason, api.RestartDirective)
+	asynctime.AfterFunc(dur, func() {
+		supervisor.RestartChildren(child)
+	})
+}
+
+func shouldStop(rs *api.RestartStatistics, maxNrOfRetries int, withinDuration time.Duration) bool {
+	if maxNrOfRetries <= 0 {
+		return true
+	}
+	rs.Fail()
+	return rs.NumberOfFailures(withinDuration) > maxNrOfRetries
+}
+
+func logFailure(child *api.Pid, reason interface{}, directive api.Directive) {
+	zlog.Warn("actor supervision",
+		zap.Uint64("uniqId", child.GetUniqId()),
+		zap.String("name", child.GetName()),
+		zap.Any("reason", reason),
+		zap.String("directive", directive.String()))
+}
@@ -0,0 +1,139 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: strategy_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/6 15:30
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Parent
+// @Description: 初始化时创建c0、c1两个子actor
+type Parent struct {
+	api.BuiltinActor
+	inits chan string
+}
+
+func (p *Parent) OnInit(ctx api.IActorContext) *api.Error {
+	_ = p.BuiltinActor.OnInit(ctx)
+	p.inits <- ctx.Self().GetName()
+	for _, name := range []string{"c0", "c1"} {
+		if _, err := ctx.Spawn(func() api.IActor { return &Child{inits: p.inits} }, nil, api.WithActorName(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Child
+// @Description: 每次初始化记录名字
+type Child struct {
+	api.BuiltinActor
+	inits chan string
+}
+
+func (c *Child) OnInit(ctx api.IActorContext) *api.Error {
+	_ = c.BuiltinActor.OnInit(ctx)
+	c.inits <- ctx.Self().GetName()
+	return nil
+}
+
+func (c *Child) Boom() *api.Error { panic("boom") }
+
+func spawnParent(t *testing.T, n *testNode, strategy api.ISupervisorStrategy) chan string {
+	inits := make(chan string, 16)
+	spawn(t, n, func() api.IActor { return &Parent{inits: inits} },
+		api.WithActorName("parent"), api.WithActorSupervisor(strategy))
+	expectInits(t, inits, "parent", "c0", "c1")
+	return inits
+}
+
+func boom(n *testNode, name string) {
+	_ = n.system.Send(nil, &api.Pid{NodeId: n.GetID(), Name: name}, "Boom", 1)
+}
+
+// expectInits
+// @Description: 按任意顺序初始化了names中的actor,之后没有其他actor初始化
+func expectInits(t *testing.T, inits chan string, names ...string) {
+	t.Helper()
+	want := make(map[string]int)
+	for _, name := range names {
+		want[name]++
+	}
+	for range names {
+		select {
+		case name := <-inits:
+			if want[name] == 0 {
+				t.Fatalf("unexpected init %s, want %v", name, names)
+			}
+			want[name]--
+		case <-time.After(time.Second):
+			t.Fatalf("inits not received, want %v", names)
+		}
+	}
+	select {
+	case name := <-inits:
+		t.Fatalf("unexpected init %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOneForOne(t *testing.T) {
+	n := newTestNode(t)
+	inits := spawnParent(t, n, NewOneForOneStrategy(2, time.Second, nil))
+	for i := 0; i < 2; i++ {
+		boom(n, "c0")
+		expectInits(t, inits, "c0")
+	}
+	// 超过重启次数停止
+	boom(n, "c0")
+	expectInits(t, inits)
+	if n.system.Find(&api.Pid{NodeId: n.GetID(), Name: "c0"}) != nil {
+		t.Fatal("child not stopped after max restarts")
+	}
+	if n.system.Find(&api.Pid{NodeId: n.GetID(), Name: "c1"}) == nil {
+		t.Fatal("sibling stopped")
+	}
+}
+
+func TestAllForOne(t *testing.T) {
+	n := newTestNode(t)
+	inits := spawnParent(t, n, NewAllForOneStrategy(2, time.Second, nil))
+	boom(n, "c0")
+	expectInits(t, inits, "c0", "c1")
+}
+
+func TestEscalate(t *testing.T) {
+	n := newTestNode(t)
+	escalate := func(_ interface{}) api.Directive { return api.EscalateDirective }
+	inits := spawnParent(t, n, NewOneForOneStrategy(2, time.Second, escalate))
+	// 父actor崩溃交给guardian,guardian重启父actor,重新创建子actor
+	boom(n, "c0")
+	expectInits(t, inits, "parent", "c0", "c1")
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	n := newTestNode(t)
+	backoff := 50 * time.Millisecond
+	inits := spawnParent(t, n, NewExponentialBackoffStrategy(time.Second, backoff, 2))
+	for i := 1; i <= 2; i++ {
+		start := time.Now()
+		boom(n, "c0")
+		expectInits(t, inits, "c0")
+		// 第i次崩溃至少等待i倍initialBackoff
+		if elapsed := time.Since(start); elapsed < time.Duration(i)*backoff {
+			t.Fatalf("restart %d after %v", i, elapsed)
+		}
+	}
+	// 超过重启次数交给上一级,guardian重启父actor
+	boom(n, "c0")
+	expectInits(t, inits, "parent", "c0", "c1")
+}
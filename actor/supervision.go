@@ -0,0 +1,205 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: supervision
+ * @Version: 1.0.0
+ * @Date: 2025/1/6 11:20
+ */
+
+package actor
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"github.com/duke-git/lancet/v2/maputil"
+	"go.uber.org/zap"
+)
+
+type failure struct {
+	who     *api.Pid
+	reason  interface{}
+	message interface{}
+}
+
+func withParent(parent *api.Pid) api.ProcessOption {
+	return func(b *api.ActorProcessOptions) {
+		b.Parent = parent
+	}
+}
+
+func postSystemMessage(system api.IActorSystem, to *api.Pid, method string, body interface{}) *api.Error {
+	process := system.Find(to)
+	if process == nil {
+		return api.ErrProcessNotExist
+	}
+	message := &api.Message{
+		Method: method,
+		To:     to,
+	}
+	message.SetBody(body)
+	return process.PostMessage(message)
+}
+
+// newGuardian
+// @Description: 顶层actor的监督者
+func newGuardian(system *System) *guardian {
+	return &guardian{
+		system:   system,
+		strategy: DefaultSupervisorStrategy(),
+		stats:    maputil.NewConcurrentMap[uint64, *api.RestartStatistics](10),
+	}
+}
+
+var _ api.ISupervisor = &guardian{}
+
+type guardian struct {
+	system   *System
+	strategy api.ISupervisorStrategy
+	stats    *maputil.ConcurrentMap[uint64, *api.RestartStatistics]
+}
+
+func (g *guardian) handleFailure(f *failure) {
+	rs, _ := g.stats.GetOrSet(f.who.GetUniqId(), api.NewRestartStatistics())
+	g.strategy.HandleFailure(g, f.who, rs, f.reason, f.message)
+}
+
+func (g *guardian) Children() []*api.Pid {
+	var children []*api.Pid
+	g.system.processDict.Range(func(_ uint64, process api.IProcess) bool {
+		if process.Context().Parent() == nil {
+			children = append(children, process.Pid())
+		}
+		return true
+	})
+	return children
+}
+
+func (g *guardian) EscalateFailure(reason interface{}, _ interface{}) {
+	zlog.Error("actor guardian can not escalate failure", zap.Any("reason", reason))
+}
+
+func (g *guardian) RestartChildren(pids ...*api.Pid) {
+	for _, pid := range pids {
+		_ = postSystemMessage(g.system, pid, api.RestartFuncName, nil)
+	}
+}
+
+func (g *guardian) StopChildren(pids ...*api.Pid) {
+	node := g.system.Node()
+	if node == nil {
+		return
+	}
+	for _, pid := range pids {
+		g.stats.Delete(pid.GetUniqId())
+		child := pid
+		// 在崩溃actor的协程中执行,需异步等待actor停止
+		node.Submit(func() {
+			_ = g.system.Kill(child)
+		}, nil)
+	}
+}
+
+func (g *guardian) ResumeChildren(_ ...*api.Pid) {}
+
+func (a *baseActorContext) Parent() *api.Pid {
+	return a.parent
+}
+
+func (a *baseActorContext) Children() []*api.Pid {
+	children := make([]*api.Pid, 0, len(a.children))
+	for id, pid := range a.children {
+		if a.System().Find(pid) == nil {
+			delete(a.children, id)
+			delete(a.childStats, id)
+			continue
+		}
+		children = append(children, pid)
+	}
+	return children
+}
+
+func (a *baseActorContext) Spawn(producer api.ActorProducer, params interface{}, opts ...api.ProcessOption) (*api.Pid, *api.Error) {
+	opts = append(opts, withParent(a.Self()))
+	pid, err := a.System().Spawn(producer, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	a.children[pid.GetUniqId()] = pid
+	return pid, nil
+}
+
+// EscalateFailure
+// @Description: actor崩溃,通知父actor处理,没有父actor由guardian处理
+// @receiver a
+// @param reason
+// @param message
+func (a *baseActorContext) EscalateFailure(reason interface{}, message interface{}) {
+	if msg, ok := message.(*api.Message); ok {
+		_ = msg.Respond(&api.RespondMessage{Err: api.ErrActorPanic})
+	}
+	f := &failure{who: a.Self(), reason: reason, message: message}
+	if a.parent != nil {
+		if err := postSystemMessage(a.System(), a.parent, api.FailureFuncName, f); err == nil {
+			return
+		}
+	}
+	if a.guardian != nil {
+		a.guardian.handleFailure(f)
+	}
+}
+
+func (a *baseActorContext) RestartChildren(pids ...*api.Pid) {
+	for _, pid := range pids {
+		_ = postSystemMessage(a.System(), pid, api.RestartFuncName, nil)
+	}
+}
+
+func (a *baseActorContext) StopChildren(pids ...*api.Pid) {
+	for _, pid := range pids {
+		_ = a.System().Kill(pid)
+		delete(a.children, pid.GetUniqId())
+		delete(a.childStats, pid.GetUniqId())
+	}
+}
+
+func (a *baseActorContext) ResumeChildren(_ ...*api.Pid) {}
+
+func (a *baseActorContext) handleFailure(f *failure) *api.Error {
+	if f == nil || f.who == nil {
+		return nil
+	}
+	rs, ok := a.childStats[f.who.GetUniqId()]
+	if !ok {
+		rs = api.NewRestartStatistics()
+		a.childStats[f.who.GetUniqId()] = rs
+	}
+	strategy := a.supervisor
+	if strategy == nil {
+		strategy = DefaultSupervisorStrategy()
+	}
+	strategy.HandleFailure(a, f.who, rs, f.reason, f.message)
+	return nil
+}
+
+// restart
+// @Description: 停止子actor,通过ActorProducer重建actor并重新初始化
+// @receiver a
+// @return *api.Error
+func (a *baseActorContext) restart() *api.Error {
+	a.cancelTimers()
+	a.StopChildren(a.Children()...)
+	if err := a.Actor().OnStop(); err != nil {
+		zlog.Error("actor restart stop err",
+			zap.Uint64("uniqId", a.Self().GetUniqId()), zap.Error(err))
+	}
+	a.actor = a.producer()
+	a.behaviors = nil
+	zlog.Info("actor restart", zap.Uint64("uniqId", a.Self().GetUniqId()), zap.String("name", a.Name()))
+	if err := a.Actor().OnInit(a); err != nil {
+		return err
+	}
+	a.resetReceiveTimeout()
+	// 缓存的消息交给新的actor处理
+	a.UnstashAll()
+	return nil
+}
@@ -9,6 +9,7 @@
 package actor
 
 import (
+	"context"
 	"github.com/dingqinghui/gas/api"
 	"github.com/dingqinghui/gas/extend/asynctime"
 	"github.com/dingqinghui/gas/extend/reflectx"
@@ -27,22 +28,47 @@ type System struct {
 	timeout     time.Duration
 	routerDict  *maputil.ConcurrentMap[string, api.IActorRouter]
 	group       *Group
+	guardian    *guardian
+	watcher     *remoteWatcher
+	deadLetter  *deadLetter
+	inbound     []api.InboundMiddleware
+	outbound    []api.OutboundMiddleware
+	activator   atomic.Pointer[api.IActivator]
+	node        api.INode
 }
 
-func NewSystem() api.IActorSystem {
-	s := new(System)
-	if api.GetNode() != nil {
-		api.GetNode().AddModule(s)
+// NewSystem
+// @Description: 创建节点的actor系统,actor通过System().Node()访问所在节点
+// @param node
+// @return api.IActorSystem
+func NewSystem(node api.INode) api.IActorSystem {
+	s := &System{node: node}
+	if s.node != nil {
+		s.node.AddModule(s)
 	}
 	return s
 }
 
+func (s *System) Node() api.INode {
+	return s.node
+}
+
+func (s *System) serializer() api.ISerializer {
+	if s.node == nil {
+		return nil
+	}
+	return s.node.Serializer()
+}
+
 func (s *System) Init() {
 	s.nameDict = maputil.NewConcurrentMap[string, *api.Pid](10)
 	s.processDict = maputil.NewConcurrentMap[uint64, api.IProcess](10)
 	s.routerDict = maputil.NewConcurrentMap[string, api.IActorRouter](10)
 	s.timeout = time.Second * 1
 	s.group = NewGroup(s)
+	s.guardian = newGuardian(s)
+	s.watcher = newRemoteWatcher()
+	s.deadLetter = newDeadLetter(s)
 }
 
 func (s *System) Name() string {
@@ -53,11 +79,11 @@ func (s *System) SetRouter(name string, router api.IActorRouter) {
 	s.routerDict.Set(name, router)
 }
 
-func (s *System) GetOrSetRouter(actor api.IActor) api.IActorRouter {
-	name := reflectx.TypeFullName(actor)
+func (s *System) GetOrSetRouter(receiver interface{}) api.IActorRouter {
+	name := reflectx.TypeFullName(receiver)
 	v, ok := s.routerDict.Get(name)
 	if !ok {
-		v, ok = s.routerDict.GetOrSet(name, NewRouter(name, actor))
+		v, ok = s.routerDict.GetOrSet(name, NewRouter(name, receiver))
 	}
 	return v
 }
@@ -70,13 +96,13 @@ func (s *System) SetTimeout(timeout time.Duration) {
 }
 
 func (s *System) Find(pid *api.Pid) api.IProcess {
-	if api.GetNode() == nil {
+	if s.node == nil {
 		return nil
 	}
 	if pid == nil {
 		return nil
 	}
-	if pid.GetNodeId() != api.GetNode().GetID() {
+	if pid.GetNodeId() != s.node.GetID() {
 		return nil
 	}
 	if pid.GetUniqId() > 0 {
@@ -138,32 +164,180 @@ func (s *System) Spawn(producer api.ActorProducer, params interface{}, opts ...a
 }
 
 func (s *System) PostMessage(to *api.Pid, message *api.Message) *api.Error {
-	node := api.GetNode()
+	node := s.node
 	if node == nil || node.Rpc() == nil {
 		return nil
 	}
 	if to == nil {
 		return api.ErrInvalidPid
 	}
+	if !api.ValidPid(to) {
+		return api.ErrInvalidPid
+	}
+	return s.outboundMessage(message, func(message *api.Message) *api.Error {
+		return s.deliver(to, message)
+	})
+}
+
+func (s *System) deliver(to *api.Pid, message *api.Message) *api.Error {
+	node := s.node
+	if !s.IsLocalPid(to) {
+		return node.Rpc().PostMessage(to, message)
+	}
+	process := s.Find(to)
+	if activator := s.getActivator(); activator != nil && s.activatable(to, process, message) {
+		return s.activate(activator, to, process, message)
+	}
+	return s.deliverLocal(to, process, message)
+}
+
+func (s *System) deliverLocal(to *api.Pid, process api.IProcess, message *api.Message) *api.Error {
+	if process == nil {
+		s.undeliverable(to, message)
+		s.deadLetter.Publish(message, api.ErrProcessNotExist)
+		return api.ErrProcessNotExist
+	}
+	err := process.PostMessage(message)
+	if err == api.ErrActorStopped {
+		s.undeliverable(to, message)
+	}
+	// 停止的actor由process记录死信
+	return err
+}
+
+// activatable
+// @Description: 按名字寻址的actor不存在或正在停止时交给激活器,监视等系统消息不激活停止中的actor
+// @receiver s
+// @param to
+// @param process
+// @param message
+// @return bool
+func (s *System) activatable(to *api.Pid, process api.IProcess, message *api.Message) bool {
+	if to.GetUniqId() != 0 || to.GetName() == "" {
+		return false
+	}
+	if process == nil {
+		return true
+	}
+	return process.IsStop() && message != nil && !api.IsSystemMethod(message.Method)
+}
+
+// SetActivator
+// @Description: 设置按名字激活actor的激活器
+// @receiver s
+// @param activator
+func (s *System) SetActivator(activator api.IActivator) {
+	s.activator.Store(&activator)
+}
+
+func (s *System) getActivator() api.IActivator {
+	if activator := s.activator.Load(); activator != nil {
+		return *activator
+	}
+	return nil
+}
+
+// activate
+// @Description: 按需激活actor,激活器返回其他节点的pid时转发消息,返回nil时消息由激活器稍后重新投递
+// @receiver s
+// @param activator
+// @param to
+// @param process 按名字找到的actor,不存在为nil
+// @param message
+// @return *api.Error
+func (s *System) activate(activator api.IActivator, to *api.Pid, process api.IProcess, message *api.Message) *api.Error {
+	pid, err := activator.Activate(to.GetName(), message)
+	if err == api.ErrProcessNotExist {
+		// 不是激活器管理的名字
+		return s.deliverLocal(to, process, message)
+	}
+	if err != nil {
+		s.deadLetter.Publish(message, err)
+		_ = message.Respond(&api.RespondMessage{Err: err})
+		return err
+	}
+	if pid == nil {
+		return nil
+	}
+	if !s.IsLocalPid(pid) {
+		return s.forward(pid, message)
+	}
+	return s.deliverLocal(pid, s.FindById(pid.GetUniqId()), message)
+}
+
+// Forward
+// @Description: 转发消息并保留回复通道,不再经过发送拦截器
+// @receiver s
+// @param to
+// @param message
+// @return *api.Error
+func (s *System) Forward(to *api.Pid, message *api.Message) *api.Error {
 	if !api.ValidPid(to) {
 		return api.ErrInvalidPid
 	}
 	if s.IsLocalPid(to) {
-		process := s.Find(to)
-		if process == nil {
-			return api.ErrProcessNotExist
-		}
-		return process.PostMessage(message)
-	} else {
+		message.To = to
+		return s.deliver(to, message)
+	}
+	return s.forward(to, message)
+}
+
+// forward
+// @Description: 转发到其他节点,调用方等待回复时通过rpc调用并回复
+// @receiver s
+// @param to
+// @param message
+// @return *api.Error
+func (s *System) forward(to *api.Pid, message *api.Message) *api.Error {
+	node := s.node
+	message.To = to
+	if !message.NeedRespond() {
 		return node.Rpc().PostMessage(to, message)
 	}
+	timeout := s.timeout
+	if deadline := message.Deadline(); !deadline.IsZero() {
+		timeout = time.Until(deadline)
+	}
+	node.Submit(func() {
+		_ = message.Respond(node.Rpc().Call(to, timeout, message))
+	}, nil)
+	return nil
+}
+
+// undeliverable
+// @Description: 监视不存在的actor,立即回复Terminated
+// @receiver s
+// @param to
+// @param message
+func (s *System) undeliverable(to *api.Pid, message *api.Message) {
+	if message == nil || message.Method != api.WatchFuncName || !api.ValidPid(message.From) {
+		return
+	}
+	terminated := &api.Terminated{Who: watchedPid(s.serializer(), message, to), Reason: api.TerminatedReasonNotExist}
+	_ = s.Send(to, message.From, api.TerminatedFuncName, terminated)
+}
+
+// HandleTopology
+// @Description: 节点离开,通知监视该节点actor的本地actor
+// @receiver s
+// @param topology
+func (s *System) HandleTopology(topology *api.Topology) {
+	if topology == nil {
+		return
+	}
+	for _, node := range topology.Left {
+		for _, pair := range s.watcher.nodeLeft(node.GetID()) {
+			terminated := &api.Terminated{Who: pair.watchee, Reason: api.TerminatedReasonNodeLeft}
+			_ = s.Send(pair.watchee, pair.watcher, api.TerminatedFuncName, terminated)
+		}
+	}
 }
 
 func (s *System) Send(from, to *api.Pid, funcName string, request interface{}) *api.Error {
-	if api.GetNode() == nil {
+	if s.node == nil {
 		return nil
 	}
-	data, err := api.GetNode().Serializer().Marshal(request)
+	data, err := s.node.Serializer().Marshal(request)
 	if err != nil {
 		return api.ErrJsonPack
 	}
@@ -172,65 +346,133 @@ func (s *System) Send(from, to *api.Pid, funcName string, request interface{}) *
 }
 
 func (s *System) Call(from, to *api.Pid, funcName string, request, reply interface{}) *api.Error {
-	if api.GetNode() == nil || api.GetNode().Rpc() == nil {
+	return s.CallContext(context.Background(), from, to, funcName, request, reply)
+}
+
+// CallContext
+// @Description: 同步调用,ctx没有deadline时使用系统默认超时,deadline随消息传递给被调用方
+// @receiver s
+// @param ctx
+// @param from
+// @param to
+// @param funcName
+// @param request
+// @param reply
+// @return *api.Error
+func (s *System) CallContext(ctx context.Context, from, to *api.Pid, funcName string, request, reply interface{}) *api.Error {
+	if s.node == nil || s.node.Rpc() == nil {
 		return nil
 	}
 	if !api.ValidPid(to) {
 		return api.ErrInvalidPid
 	}
-	requestData, e := api.GetNode().Serializer().Marshal(request)
-	if e != nil {
-		zlog.Error("system call", zap.Error(api.ErrJsonPack))
-		return api.ErrJsonPack
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
 	}
-	message := api.BuildInnerMessage(from, to, funcName, requestData)
-
+	deadline, _ := ctx.Deadline()
+	f := s.requestFuture(from, to, funcName, request, deadline)
 	var rsp *api.RespondMessage
-	if s.IsLocalPid(to) {
-		process := s.Find(to)
-		if process == nil {
-			return api.ErrProcessNotExist
+	select {
+	case <-f.done:
+		rsp = f.rsp
+	case <-ctx.Done():
+		if ctx.Err() == context.Canceled {
+			return api.ErrCallCanceled
 		}
-		rsp = process.PostMessageAndWait(message)
-	} else {
-		rsp = api.GetNode().Rpc().Call(to, s.timeout, message)
-	}
-	if rsp == nil {
-		return nil
+		return api.ErrActorCallTimeout
 	}
 	if !api.IsOk(rsp.Err) {
 		return rsp.Err
 	}
-	if err := s.unmarshalRsp(rsp, reply); err != nil {
+	if err := unmarshalRsp(s.serializer(), rsp, reply); err != nil {
 		zlog.Error("system call", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (s *System) unmarshalRsp(rsp *api.RespondMessage, reply interface{}) *api.Error {
-	if api.GetNode() == nil {
+// RequestFuture
+// @Description: 异步请求,不阻塞调用者
+// @receiver s
+// @param from
+// @param to
+// @param funcName
+// @param request
+// @return api.IFuture
+func (s *System) RequestFuture(from, to *api.Pid, funcName string, request interface{}) api.IFuture {
+	return s.requestFuture(from, to, funcName, request, time.Now().Add(s.timeout))
+}
+
+func (s *System) requestFuture(from, to *api.Pid, funcName string, request interface{}, deadline time.Time) *future {
+	f := newFuture(time.Until(deadline))
+	// 截止时间已过,不再发送
+	if f.isDone() {
+		return f
+	}
+	node := s.node
+	if node == nil || node.Rpc() == nil {
+		f.complete(nil)
+		return f
+	}
+	if !api.ValidPid(to) {
+		f.complete(&api.RespondMessage{Err: api.ErrInvalidPid})
+		return f
+	}
+	requestData, e := node.Serializer().Marshal(request)
+	if e != nil {
+		zlog.Error("system request future", zap.Error(api.ErrJsonPack))
+		f.complete(&api.RespondMessage{Err: api.ErrJsonPack})
+		return f
+	}
+	message := api.BuildInnerMessage(from, to, funcName, requestData)
+	message.SetDeadline(deadline)
+	if s.IsLocalPid(to) {
+		message.SetRespond(func(rsp *api.RespondMessage) *api.Error {
+			f.complete(rsp)
+			return nil
+		})
+		if err := s.PostMessage(to, message); err != nil {
+			f.complete(&api.RespondMessage{Err: err})
+		}
+		return f
+	}
+	err := s.outboundMessage(message, func(message *api.Message) *api.Error {
+		node.Submit(func() {
+			f.complete(node.Rpc().Call(to, time.Until(deadline), message))
+		}, nil)
+		return nil
+	})
+	if err != nil {
+		f.complete(&api.RespondMessage{Err: err})
+	}
+	return f
+}
+
+func unmarshalRsp(serializer api.ISerializer, rsp *api.RespondMessage, reply interface{}) *api.Error {
+	if serializer == nil {
 		return nil
 	}
 	if rsp.Data == nil {
 		return nil
 	}
-	if err := api.GetNode().Serializer().Unmarshal(rsp.Data, reply); err != nil {
+	if err := serializer.Unmarshal(rsp.Data, reply); err != nil {
 		return api.ErrJsonUnPack
 	}
 	return nil
 }
 
 func (s *System) IsLocalPid(pid *api.Pid) bool {
-	if api.GetNode() == nil {
+	if s.node == nil {
 		return false
 	}
-	return pid.GetNodeId() == api.GetNode().GetID()
+	return pid.GetNodeId() == s.node.GetID()
 }
 
 func (s *System) NextPid() *api.Pid {
 	return &api.Pid{
-		NodeId: api.GetNode().GetID(),
+		NodeId: s.node.GetID(),
 		UniqId: s.uniqId.Add(1),
 	}
 }
@@ -249,13 +491,22 @@ func (s *System) spawn(producer api.ActorProducer, params interface{}, opts ...a
 	context.pid = s.NextPid()
 	context.initParams = params
 	context.name = name
+	context.producer = producer
+	context.parent = opt.Parent
+	context.supervisor = opt.Supervisor
+	context.guardian = s.guardian
+	context.remoteWatcher = s.watcher
+	context.idle.duration = opt.ReceiveTimeout
+	context.idle.autoStop = opt.StopOnReceiveTimeout
+	context.inbound = inboundChain(s, opt, context.invokeMethod)
+	context.outbound = opt.Outbound
 
 	process := NewBaseProcess(context, mb)
 	context.process = process
 
 	_ = s.RegisterName(name, context.pid)
 
-	mb.RegisterHandlers(context, getDispatcher(opt))
+	mb.RegisterHandlers(context, getDispatcher(s.node, opt))
 	// notify actor start
 	message := &api.Message{
 		Method: api.InitFuncName,
@@ -292,9 +543,29 @@ func (s *System) Kill(pid *api.Pid) *api.Error {
 	}
 	s.nameDict.Delete(pid.GetName())
 	s.processDict.Delete(pid.GetUniqId())
+	s.guardian.stats.Delete(pid.GetUniqId())
 	return nil
 }
 
+// Load
+// @Description: actor数量和所有邮箱积压的消息数量
+// @receiver s
+// @return actors
+// @return backlog
+func (s *System) Load() (actors, backlog int) {
+	s.processDict.Range(func(_ uint64, process api.IProcess) bool {
+		if process == nil {
+			return true
+		}
+		actors++
+		if mb := process.Mailbox(); mb != nil {
+			backlog += mb.Len()
+		}
+		return true
+	})
+	return
+}
+
 func (s *System) Stop() *api.Error {
 	if err := s.BuiltinStopper.Stop(); err != nil {
 		return err
@@ -312,6 +583,10 @@ func (s *System) Stop() *api.Error {
 	return nil
 }
 
+func (s *System) DeadLetter() api.IDeadLetter {
+	return s.deadLetter
+}
+
 func (s *System) Group() api.IGroup {
 	return s.group
 }
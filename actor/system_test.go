@@ -0,0 +1,101 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: system_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/6 11:20
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/serializer"
+	"github.com/spf13/viper"
+)
+
+// testRpc
+// @Description: 发往其他节点的消息直接丢弃,调用返回节点不存在
+type testRpc struct{ api.BuiltinModule }
+
+func (r *testRpc) Call(_ *api.Pid, _ time.Duration, _ *api.Message) *api.RespondMessage {
+	return &api.RespondMessage{Err: api.ErrRpcNodeNotExist}
+}
+func (r *testRpc) PostMessage(_ *api.Pid, _ *api.Message) *api.Error { return nil }
+func (r *testRpc) Broadcast(_ *api.Message) *api.Error               { return nil }
+
+// testNode
+// @Description: 只有actor系统的单节点
+type testNode struct {
+	api.BuiltinModule
+	*api.BaseNode
+	system api.IActorSystem
+	rpc    *testRpc
+}
+
+func newTestNode(t *testing.T) *testNode {
+	n := &testNode{BaseNode: &api.BaseNode{Id: 1}, rpc: new(testRpc)}
+	n.system = NewSystem(n)
+	n.system.Init()
+	t.Cleanup(func() { _ = n.system.Stop() })
+	return n
+}
+
+func (n *testNode) Init()                                  {}
+func (n *testNode) Run()                                   {}
+func (n *testNode) Wait()                                  {}
+func (n *testNode) GetViper() *viper.Viper                 { return viper.New() }
+func (n *testNode) System() api.IActorSystem               { return n.system }
+func (n *testNode) Discovery() api.IDiscovery              { return nil }
+func (n *testNode) Rpc() api.IRpc                          { return n.rpc }
+func (n *testNode) Base() api.INodeBase                    { return n.BaseNode }
+func (n *testNode) NextId() int64                          { return 1 }
+func (n *testNode) AddModule(_ ...api.IModule)             {}
+func (n *testNode) Terminate(_ string)                     {}
+func (n *testNode) Serializer() api.ISerializer            { return serializer.Json }
+func (n *testNode) Name() string                           { return "test" }
+func (n *testNode) Submit(fn func(), re func(interface{})) { go n.Try(fn, re) }
+func (n *testNode) Try(fn func(), re func(interface{})) {
+	defer func() {
+		if err := recover(); err != nil && re != nil {
+			re(err)
+		}
+	}()
+	fn()
+}
+
+// Echo
+// @Description: 测试用actor
+type Echo struct {
+	api.BuiltinActor
+}
+
+func (e *Echo) Echo(v *int) (*int, *api.Error) { return v, nil }
+func (e *Echo) Boom() *api.Error               { panic("boom") }
+func (e *Echo) Sleep(ms *int) *api.Error {
+	time.Sleep(time.Duration(*ms) * time.Millisecond)
+	return nil
+}
+
+func spawn(t *testing.T, n *testNode, producer api.ActorProducer, opts ...api.ProcessOption) *api.Pid {
+	pid, err := n.system.Spawn(producer, nil, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
+
+func TestSystemCall(t *testing.T) {
+	n := newTestNode(t)
+	pid := spawn(t, n, func() api.IActor { return new(Echo) })
+	v, reply := 3, 0
+	if err := n.system.Call(nil, pid, "Echo", &v, &reply); err != nil || reply != 3 {
+		t.Fatalf("call = %v %d", err, reply)
+	}
+	if err := n.system.Call(nil, pid, "Nope", &v, &reply); err != api.ErrActorNotMethod {
+		t.Fatalf("call unknown method = %v", err)
+	}
+}
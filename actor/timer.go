@@ -0,0 +1,127 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: timer
+ * @Version: 1.0.0
+ * @Date: 2025/1/13 14:30
+ */
+
+package actor
+
+import (
+	"github.com/RussellLuo/timingwheel"
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/asynctime"
+	"time"
+)
+
+type actorTimer struct {
+	repeat  bool
+	payload interface{}
+	fun     api.TimerFunc
+	timer   *timingwheel.Timer
+}
+
+// AfterFunc
+// @Description: 一次性定时器,回调在actor邮箱中执行,actor停止时自动取消
+// @receiver a
+// @param d
+// @param payload 透传给回调
+// @param f
+// @return uint64 定时器id,0表示失败
+func (a *baseActorContext) AfterFunc(d time.Duration, payload interface{}, f api.TimerFunc) uint64 {
+	if f == nil {
+		return 0
+	}
+	return a.addTimer(false, payload, f, func(fire func()) *timingwheel.Timer {
+		return asynctime.AfterFunc(d, fire)
+	})
+}
+
+// Every
+// @Description: 周期定时器
+func (a *baseActorContext) Every(d time.Duration, payload interface{}, f api.TimerFunc) uint64 {
+	if f == nil || d <= 0 {
+		return 0
+	}
+	return a.addTimer(true, payload, f, func(fire func()) *timingwheel.Timer {
+		return asynctime.Every(d, fire)
+	})
+}
+
+// Cron
+// @Description: cron表达式定时器
+func (a *baseActorContext) Cron(spec string, payload interface{}, f api.TimerFunc) (uint64, *api.Error) {
+	if f == nil {
+		return 0, api.ErrInvalidCronSpec
+	}
+	schedule, err := asynctime.ParseCron(spec)
+	if err != nil {
+		return 0, api.ErrInvalidCronSpec
+	}
+	id := a.addTimer(true, payload, f, func(fire func()) *timingwheel.Timer {
+		return asynctime.Schedule(schedule, fire)
+	})
+	return id, nil
+}
+
+func (a *baseActorContext) CancelTimer(timerId uint64) {
+	t, ok := a.timers[timerId]
+	if !ok {
+		return
+	}
+	delete(a.timers, timerId)
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+func (a *baseActorContext) addTimer(repeat bool, payload interface{}, f api.TimerFunc, start func(fire func()) *timingwheel.Timer) uint64 {
+	a.timerSeq++
+	id := a.timerSeq
+	process := a.Process()
+	self := a.Self()
+	t := &actorTimer{
+		repeat:  repeat,
+		payload: payload,
+		fun:     f,
+	}
+	a.timers[id] = t
+	t.timer = start(func() {
+		message := &api.Message{
+			Method: api.TimerFuncName,
+			From:   self,
+			To:     self,
+		}
+		message.SetBody(id)
+		_ = process.PostMessage(message)
+	})
+	return id
+}
+
+// handleTimer
+// @Description: 已取消的定时器忽略
+// @receiver a
+// @param msg
+// @return *api.Error
+func (a *baseActorContext) handleTimer(msg *api.Message) *api.Error {
+	id, ok := msg.Body().(uint64)
+	if !ok {
+		return nil
+	}
+	t, ok := a.timers[id]
+	if !ok {
+		return nil
+	}
+	if !t.repeat {
+		delete(a.timers, id)
+	}
+	t.fun(id, t.payload)
+	return nil
+}
+
+func (a *baseActorContext) cancelTimers() {
+	for id := range a.timers {
+		a.CancelTimer(id)
+	}
+}
@@ -0,0 +1,131 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: timer_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/13 16:10
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Ticker
+// @Description: 通过消息设置定时器,触发时把payload写入ticks
+type Ticker struct {
+	api.BuiltinActor
+	ticks chan interface{}
+	every uint64
+}
+
+func (k *Ticker) tick(_ uint64, payload interface{}) {
+	k.ticks <- payload
+}
+
+func (k *Ticker) After(ms *int) *api.Error {
+	k.Ctx.AfterFunc(time.Duration(*ms)*time.Millisecond, "after", k.tick)
+	return nil
+}
+
+func (k *Ticker) Every(ms *int) *api.Error {
+	k.every = k.Ctx.Every(time.Duration(*ms)*time.Millisecond, "every", k.tick)
+	return nil
+}
+
+func (k *Ticker) Cancel() *api.Error {
+	k.Ctx.CancelTimer(k.every)
+	return nil
+}
+
+func (k *Ticker) Cron(spec *string) *api.Error {
+	_, err := k.Ctx.Cron(*spec, "cron", k.tick)
+	return err
+}
+
+func spawnTicker(t *testing.T, n *testNode) (*api.Pid, chan interface{}) {
+	ticks := make(chan interface{}, 64)
+	return spawn(t, n, func() api.IActor { return &Ticker{ticks: ticks} }), ticks
+}
+
+func expectTick(t *testing.T, ticks chan interface{}, payload string, timeout time.Duration) {
+	t.Helper()
+	select {
+	case got := <-ticks:
+		if got != payload {
+			t.Fatalf("tick = %v, want %s", got, payload)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("%s not fired", payload)
+	}
+}
+
+func expectNoTick(t *testing.T, ticks chan interface{}) {
+	t.Helper()
+	time.Sleep(20 * time.Millisecond)
+	for len(ticks) > 0 {
+		<-ticks
+	}
+	select {
+	case got := <-ticks:
+		t.Fatalf("unexpected tick %v", got)
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestTimer(t *testing.T) {
+	n := newTestNode(t)
+	pid, ticks := spawnTicker(t, n)
+	ms := 10
+	if err := n.system.Call(nil, pid, "After", &ms, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectTick(t, ticks, "after", time.Second)
+	expectNoTick(t, ticks)
+
+	if err := n.system.Call(nil, pid, "Every", &ms, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		expectTick(t, ticks, "every", time.Second)
+	}
+	if err := n.system.Call(nil, pid, "Cancel", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectNoTick(t, ticks)
+}
+
+func TestTimerStop(t *testing.T) {
+	n := newTestNode(t)
+	pid, ticks := spawnTicker(t, n)
+	ms := 10
+	_ = n.system.Call(nil, pid, "Every", &ms, nil)
+	expectTick(t, ticks, "every", time.Second)
+	// actor停止时取消定时器
+	ctx := n.system.Find(pid).Context().(*baseActorContext)
+	if err := n.system.Kill(pid); err != nil {
+		t.Fatal(err)
+	}
+	if len(ctx.timers) != 0 {
+		t.Fatalf("timers after stop = %d", len(ctx.timers))
+	}
+	expectNoTick(t, ticks)
+}
+
+func TestCronTimer(t *testing.T) {
+	n := newTestNode(t)
+	pid, ticks := spawnTicker(t, n)
+	spec := "* * * *"
+	if err := n.system.Call(nil, pid, "Cron", &spec, nil); err != api.ErrInvalidCronSpec {
+		t.Fatalf("invalid spec = %v", err)
+	}
+	spec = "* * * * * *"
+	if err := n.system.Call(nil, pid, "Cron", &spec, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectTick(t, ticks, "cron", 2*time.Second)
+}
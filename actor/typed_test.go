@@ -0,0 +1,148 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: typed_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/18 15:20
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+// Shifter
+// @Description: 每次调用在actor和Shifted之间切换处理对象
+type Shifter struct {
+	api.BuiltinActor
+}
+
+func (s *Shifter) Echo(v *int) (*int, *api.Error) {
+	return v, s.Ctx.Become(&Shifted{shifter: s})
+}
+
+// Shifted
+// @Description: Shifter切换后的处理对象
+type Shifted struct {
+	shifter *Shifter
+}
+
+func (s *Shifted) Echo(v *int) (*int, *api.Error) {
+	s.shifter.Ctx.Unbecome()
+	return v, nil
+}
+
+var noticeGroup = api.NewTypedGroup[*int]("Notice")
+
+// Listener
+// @Description: 初始化时加入noticeGroup
+type Listener struct {
+	api.BuiltinActor
+	notices chan int
+}
+
+func (l *Listener) OnInit(ctx api.IActorContext) *api.Error {
+	_ = l.BuiltinActor.OnInit(ctx)
+	return noticeGroup.Join(ctx)
+}
+
+func (l *Listener) Notice(v *int) *api.Error {
+	l.notices <- *v
+	return nil
+}
+
+func callerContext(t *testing.T, n *testNode) api.IActorContext {
+	return n.system.Find(spawn(t, n, func() api.IActor { return new(Echo) })).Context()
+}
+
+func TestTypedCall(t *testing.T) {
+	n := newTestNode(t)
+	ctx := callerContext(t, n)
+	pid := spawn(t, n, func() api.IActor { return new(Echo) })
+	v := 3
+	if rsp, err := api.Call[*int, *int](ctx, pid, "Echo", &v); err != nil || *rsp != 3 {
+		t.Fatalf("call = %v %v", rsp, err)
+	}
+	if rsp, err := api.Call[int, int](ctx, pid, "Echo", v); err != nil || rsp != 3 {
+		t.Fatalf("value call = %v %v", rsp, err)
+	}
+	if _, err := api.Call[*int, *string](ctx, pid, "Echo", &v); err != api.ErrActorReplyType {
+		t.Fatalf("wrong reply = %v", err)
+	}
+	if _, err := api.Call[*string, *int](ctx, pid, "Echo", new(string)); err != api.ErrActorRequestType {
+		t.Fatalf("wrong request = %v", err)
+	}
+	if err := api.Send[*string](ctx, pid, "Echo", new(string)); err != api.ErrActorRequestType {
+		t.Fatalf("wrong send = %v", err)
+	}
+	if _, err := api.Call[*int, *int](ctx, pid, "Nope", &v); err != api.ErrActorNotMethod {
+		t.Fatalf("unknown method = %v", err)
+	}
+
+	ref, err := api.NewMethodRef[*int, *int](n.system, new(Echo), "Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp, err := ref.Call(ctx, pid, &v); err != nil || *rsp != 3 {
+		t.Fatalf("method ref call = %v %v", rsp, err)
+	}
+	if _, err := api.NewMethodRef[*int, *int](n.system, new(Echo), "Nope"); err != api.ErrActorNotMethod {
+		t.Fatalf("unknown method ref = %v", err)
+	}
+	if _, err := api.NewMethodRef[*int, int](n.system, new(Echo), "Boom"); err != api.ErrActorReplyType {
+		t.Fatalf("method ref without reply = %v", err)
+	}
+}
+
+func TestTypedCallBecome(t *testing.T) {
+	n := newTestNode(t)
+	ctx := callerContext(t, n)
+	pid := spawn(t, n, func() api.IActor { return new(Shifter) })
+	// 目标actor切换处理对象的同时校验方法签名
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			v := i
+			_ = n.system.Send(nil, pid, "Echo", &v)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if rsp, err := api.Call[int, int](ctx, pid, "Echo", i); err != nil || rsp != i {
+			t.Fatalf("call = %v %v", rsp, err)
+		}
+	}
+	<-done
+}
+
+func TestTypedGroup(t *testing.T) {
+	n := newTestNode(t)
+	ctx := callerContext(t, n)
+	notices := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		spawn(t, n, func() api.IActor { return &Listener{notices: notices} })
+	}
+	time.Sleep(10 * time.Millisecond)
+	v := 7
+	if err := noticeGroup.Broadcast(ctx, &v); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case got := <-notices:
+			if got != 7 {
+				t.Fatalf("notice = %d", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("notice not received")
+		}
+	}
+	// 没有同名方法的actor不能加入
+	if err := noticeGroup.Join(ctx); err != api.ErrActorNotMethod {
+		t.Fatalf("join without method = %v", err)
+	}
+}
@@ -35,5 +35,8 @@ func (f *chanWaiter) Wait() (*api.RespondMessage, *api.Error) {
 }
 
 func (f *chanWaiter) Done(message *api.RespondMessage) {
-	f.ch <- message
+	select {
+	case f.ch <- message:
+	default:
+	}
 }
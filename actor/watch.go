@@ -0,0 +1,211 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: watch
+ * @Version: 1.0.0
+ * @Date: 2025/1/8 15:02
+ */
+
+package actor
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"sync"
+)
+
+type watchPair struct {
+	watcher *api.Pid
+	watchee *api.Pid
+}
+
+func newRemoteWatcher() *remoteWatcher {
+	return &remoteWatcher{
+		dict: make(map[uint64]map[string]*watchPair),
+	}
+}
+
+// remoteWatcher
+// @Description: 记录监视远程actor的本地actor,远程节点离开时通知监视者
+type remoteWatcher struct {
+	sync.Mutex
+	dict map[uint64]map[string]*watchPair
+}
+
+func (r *remoteWatcher) add(watcher, watchee *api.Pid) {
+	r.Lock()
+	defer r.Unlock()
+	pairs, ok := r.dict[watchee.GetNodeId()]
+	if !ok {
+		pairs = make(map[string]*watchPair)
+		r.dict[watchee.GetNodeId()] = pairs
+	}
+	pairs[watchPairKey(watcher, watchee)] = &watchPair{watcher: watcher, watchee: watchee}
+}
+
+func (r *remoteWatcher) remove(watcher, watchee *api.Pid) {
+	r.Lock()
+	defer r.Unlock()
+	pairs, ok := r.dict[watchee.GetNodeId()]
+	if !ok {
+		return
+	}
+	delete(pairs, watchPairKey(watcher, watchee))
+	if len(pairs) == 0 {
+		delete(r.dict, watchee.GetNodeId())
+	}
+}
+
+func (r *remoteWatcher) nodeLeft(nodeId uint64) []*watchPair {
+	r.Lock()
+	defer r.Unlock()
+	pairs, ok := r.dict[nodeId]
+	if !ok {
+		return nil
+	}
+	delete(r.dict, nodeId)
+	result := make([]*watchPair, 0, len(pairs))
+	for _, pair := range pairs {
+		result = append(result, pair)
+	}
+	return result
+}
+
+func (a *baseActorContext) Watch(pid *api.Pid) *api.Error {
+	if !api.ValidPid(pid) {
+		return api.ErrInvalidPid
+	}
+	a.watching[pid.Key()] = pid
+	if !a.System().IsLocalPid(pid) && a.remoteWatcher != nil {
+		a.remoteWatcher.add(a.Self(), pid)
+	}
+	return a.postWatch(pid, api.WatchFuncName)
+}
+
+func (a *baseActorContext) Unwatch(pid *api.Pid) *api.Error {
+	if !api.ValidPid(pid) {
+		return api.ErrInvalidPid
+	}
+	if _, ok := a.watching[pid.Key()]; !ok {
+		return nil
+	}
+	delete(a.watching, pid.Key())
+	if !a.System().IsLocalPid(pid) && a.remoteWatcher != nil {
+		a.remoteWatcher.remove(a.Self(), pid)
+	}
+	return a.postWatch(pid, api.UnwatchFuncName)
+}
+
+// postWatch
+// @Description: 监视消息携带监视者使用的pid,被监视者停止时原样带回,按名字寻址或转发后也能匹配
+// @receiver a
+// @param pid
+// @param method
+// @return *api.Error
+func (a *baseActorContext) postWatch(pid *api.Pid, method string) *api.Error {
+	serializer := a.serializer()
+	if serializer == nil {
+		return nil
+	}
+	data, err := serializer.Marshal(pid)
+	if err != nil {
+		return api.ErrMarshal
+	}
+	message := api.BuildInnerMessage(a.Self(), pid, method, data)
+	if err := a.System().PostMessage(pid, message); err != nil && err != api.ErrProcessNotExist {
+		return err
+	}
+	return nil
+}
+
+// watchedPid
+// @Description: 监视消息中监视者使用的pid,没有时用self
+// @param serializer
+// @param msg
+// @param self
+// @return *api.Pid
+func watchedPid(serializer api.ISerializer, msg *api.Message, self *api.Pid) *api.Pid {
+	watched := new(api.Pid)
+	if serializer != nil && len(msg.Data) > 0 && serializer.Unmarshal(msg.Data, watched) == nil && api.ValidPid(watched) {
+		return watched
+	}
+	return self
+}
+
+func (a *baseActorContext) handleWatch(msg *api.Message) *api.Error {
+	if !api.ValidPid(msg.From) {
+		return api.ErrInvalidPid
+	}
+	watched := watchedPid(a.serializer(), msg, a.Self())
+	a.watchers[watchPairKey(msg.From, watched)] = &watchPair{watcher: msg.From, watchee: watched}
+	return nil
+}
+
+func (a *baseActorContext) handleUnwatch(msg *api.Message) *api.Error {
+	if msg.From == nil {
+		return nil
+	}
+	delete(a.watchers, watchPairKey(msg.From, watchedPid(a.serializer(), msg, a.Self())))
+	return nil
+}
+
+// handleTerminated
+// @Description: 被监视actor停止,监视中则转发给actor的OnTerminated
+// @receiver a
+// @param msg
+// @return *api.Error
+func (a *baseActorContext) handleTerminated(msg *api.Message) *api.Error {
+	serializer := a.serializer()
+	if serializer == nil {
+		return nil
+	}
+	terminated := new(api.Terminated)
+	if err := serializer.Unmarshal(msg.Data, terminated); err != nil {
+		return api.ErrUnmarshal
+	}
+	who := terminated.Who
+	if who == nil {
+		return nil
+	}
+	if who.GetNodeId() == a.Self().GetNodeId() {
+		delete(a.children, who.GetUniqId())
+		delete(a.childStats, who.GetUniqId())
+	}
+	if _, ok := a.watching[who.Key()]; !ok {
+		return nil
+	}
+	delete(a.watching, who.Key())
+	if !a.System().IsLocalPid(who) && a.remoteWatcher != nil {
+		a.remoteWatcher.remove(a.Self(), who)
+	}
+	if router := a.Router(); router == nil || router.Get(api.TerminatedFuncName) == nil {
+		return nil
+	}
+	return a.invokerInnerMessage(msg)
+}
+
+// notifyTerminated
+// @Description: actor停止,通知所有监视者和父actor,并取消自己的监视
+// @receiver a
+func (a *baseActorContext) notifyTerminated() {
+	parentNotified := false
+	for _, pair := range a.watchers {
+		terminated := &api.Terminated{Who: pair.watchee, Reason: api.TerminatedReasonStopped}
+		_ = a.System().Send(a.Self(), pair.watcher, api.TerminatedFuncName, terminated)
+		if pair.watcher.Key() == a.parent.Key() && pair.watchee.Key() == a.Self().Key() {
+			parentNotified = true
+		}
+	}
+	// 父actor按UniqId记录子actor,需要收到自身pid
+	if a.parent != nil && !parentNotified {
+		terminated := &api.Terminated{Who: a.Self(), Reason: api.TerminatedReasonStopped}
+		_ = a.System().Send(a.Self(), a.parent, api.TerminatedFuncName, terminated)
+	}
+	a.watchers = make(map[string]*watchPair)
+	for _, pid := range a.watching {
+		_ = a.Unwatch(pid)
+	}
+}
+
+func watchPairKey(watcher, watchee *api.Pid) string {
+	return watcher.Key() + "-" + watchee.Key()
+}
@@ -0,0 +1,82 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: watch_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/8 15:02
+ */
+
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+type Watcher struct {
+	api.BuiltinActor
+	terminated chan *api.Terminated
+}
+
+func (w *Watcher) Watch(pid *api.Pid) *api.Error { return w.Ctx.Watch(pid) }
+func (w *Watcher) OnTerminated(terminated *api.Terminated) *api.Error {
+	w.terminated <- terminated
+	return nil
+}
+
+func waitTerminated(t *testing.T, ch chan *api.Terminated) *api.Terminated {
+	select {
+	case terminated := <-ch:
+		return terminated
+	case <-time.After(time.Second):
+		t.Fatal("no terminated")
+	}
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	n := newTestNode(t)
+	ch := make(chan *api.Terminated, 4)
+	watcher := spawn(t, n, func() api.IActor { return &Watcher{terminated: ch} })
+
+	// 按名字监视,收到的Who与监视时一致
+	target := spawn(t, n, func() api.IActor { return new(Echo) }, api.WithActorName("target"))
+	byName := &api.Pid{NodeId: 1, Name: "target"}
+	if err := n.system.Call(nil, watcher, "Watch", byName, nil); err != nil {
+		t.Fatal(err)
+	}
+	_ = n.system.Kill(target)
+	if terminated := waitTerminated(t, ch); terminated.Who.Key() != byName.Key() || terminated.Reason != api.TerminatedReasonStopped {
+		t.Fatalf("terminated = %v %v", terminated.Who, terminated.Reason)
+	}
+
+	// 监视时pid名字与实际不同,仍按UniqId匹配
+	target = spawn(t, n, func() api.IActor { return new(Echo) })
+	stale := &api.Pid{NodeId: target.NodeId, UniqId: target.UniqId, Name: "stale"}
+	if err := n.system.Call(nil, watcher, "Watch", stale, nil); err != nil {
+		t.Fatal(err)
+	}
+	_ = n.system.Kill(target)
+	if terminated := waitTerminated(t, ch); terminated.Who.Key() != target.Key() {
+		t.Fatalf("terminated = %v", terminated.Who)
+	}
+
+	// 监视不存在的actor
+	missing := &api.Pid{NodeId: 1, UniqId: 999}
+	_ = n.system.Send(nil, watcher, "Watch", missing)
+	if terminated := waitTerminated(t, ch); terminated.Who.Key() != missing.Key() || terminated.Reason != api.TerminatedReasonNotExist {
+		t.Fatalf("terminated = %v %v", terminated.Who, terminated.Reason)
+	}
+
+	// 远程节点离开
+	remote := &api.Pid{NodeId: 7, Name: "remote"}
+	if err := n.system.Call(nil, watcher, "Watch", remote, nil); err != nil {
+		t.Fatal(err)
+	}
+	n.system.HandleTopology(&api.Topology{Left: []api.INodeBase{&api.BaseNode{Id: 7}}})
+	if terminated := waitTerminated(t, ch); terminated.Who.Key() != remote.Key() || terminated.Reason != api.TerminatedReasonNodeLeft {
+		t.Fatalf("terminated = %v %v", terminated.Who, terminated.Reason)
+	}
+}
@@ -9,18 +9,43 @@
 package api
 
 import (
+	"context"
 	"github.com/dingqinghui/gas/extend/reflectx"
 	"time"
 )
 
+// MailboxOverflowPolicy
+// @Description: 有界邮箱满时的处理策略
+type MailboxOverflowPolicy int
+
+const (
+	MailboxOverflowReject     MailboxOverflowPolicy = iota // 拒绝新消息,返回ErrMailboxFull
+	MailboxOverflowDropOldest                              // 丢弃最早的消息
+	MailboxOverflowDropNewest                              // 丢弃新消息
+	MailboxOverflowBlock                                   // 阻塞投递者直到超时
+)
+
+// MessagePriority
+// @Description: 用户消息优先级
+type MessagePriority int
+
+const (
+	PriorityLow MessagePriority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
 type (
 	ActorProducer        func() IActor
 	IActorMessageInvoker interface {
 		InvokerMessage(message interface{}) *Error
+		EscalateFailure(reason interface{}, message interface{})
 	}
 	IActorMailbox interface {
 		PostMessage(msg interface{}) *Error
+		PostSystemMessage(msg interface{}) *Error
 		RegisterHandlers(invoker IActorMessageInvoker, dispatcher IActorDispatcher)
+		Len() int
 	}
 	IActorDispatcher interface {
 		Schedule(f func(), recoverFun func(err interface{})) *Error
@@ -34,11 +59,31 @@ type (
 		Actor() IActor
 		Self() *Pid
 		InitParams() interface{}
+		Parent() *Pid
+		Children() []*Pid
+		Spawn(producer ActorProducer, params interface{}, opts ...ProcessOption) (*Pid, *Error)
+		Watch(pid *Pid) *Error
+		Unwatch(pid *Pid) *Error
 		RegisterName(name string) *Error
 		UnregisterName(name string) (*Pid, *Error)
 		Router() IActorRouter
+		BaseRouter() IActorRouter
 		Send(to *Pid, funcName string, request interface{}) *Error
 		Call(to *Pid, funcName string, request, reply interface{}) *Error
+		CallTimeout(to *Pid, timeout time.Duration, funcName string, request, reply interface{}) *Error
+		CallContext(ctx context.Context, to *Pid, funcName string, request, reply interface{}) *Error
+		RequestFuture(to *Pid, funcName string, request interface{}) IFuture
+		CallAsync(to *Pid, funcName string, request, reply interface{}, callback AsyncCallback) *Error
+		AfterFunc(d time.Duration, payload interface{}, f TimerFunc) uint64
+		Every(d time.Duration, payload interface{}, f TimerFunc) uint64
+		Cron(spec string, payload interface{}, f TimerFunc) (uint64, *Error)
+		CancelTimer(timerId uint64)
+		SetReceiveTimeout(d time.Duration)
+		ReceiveTimeout() time.Duration
+		Become(behavior interface{}) *Error
+		Unbecome()
+		Stash() *Error
+		UnstashAll()
 		AddGroup(eventName string)
 		RemoveGroup(eventName string)
 		BroadcastGroup(eventName string, msg interface{}) *Error
@@ -51,6 +96,7 @@ type (
 		PostMessage(message *Message) *Error
 		PostMessageAndWait(message *Message) (rsp *RespondMessage)
 		Stop() *Error
+		Mailbox() IActorMailbox
 	}
 
 	IActor interface {
@@ -60,6 +106,7 @@ type (
 
 	IActorSystem interface {
 		IModule
+		Node() INode
 		Spawn(producer ActorProducer, params interface{}, opts ...ProcessOption) (*Pid, *Error)
 		NextPid() *Pid
 		Kill(pid *Pid) *Error
@@ -68,14 +115,36 @@ type (
 		RegisterName(name string, pid *Pid) *Error
 		UnregisterName(name string) (*Pid, *Error)
 		PostMessage(to *Pid, message *Message) *Error
+		Forward(to *Pid, message *Message) *Error
 		Send(from, to *Pid, funcName string, request interface{}) *Error
 		Call(from, to *Pid, funcName string, request, reply interface{}) *Error
+		CallContext(ctx context.Context, from, to *Pid, funcName string, request, reply interface{}) *Error
+		RequestFuture(from, to *Pid, funcName string, request interface{}) IFuture
 		Timeout() time.Duration
 		SetTimeout(timeout time.Duration)
 		IsLocalPid(pid *Pid) bool
 		SetRouter(name string, router IActorRouter)
-		GetOrSetRouter(actor IActor) IActorRouter
+		GetOrSetRouter(receiver interface{}) IActorRouter
 		Group() IGroup
+		DeadLetter() IDeadLetter
+		UseInbound(middlewares ...InboundMiddleware)
+		UseOutbound(middlewares ...OutboundMiddleware)
+		SetActivator(activator IActivator)
+		Load() (actors, backlog int)
+		HandleTopology(topology *Topology)
+	}
+
+	// IForwarder
+	// @Description: actor实现该接口后,没有对应方法的消息交给Forward处理,用于代理actor
+	IForwarder interface {
+		Forward(ctx IActorContext, msg *Message) *Error
+	}
+
+	// IActivator
+	// @Description: 按名字投递的消息找不到本地actor或actor正在停止时按需激活,返回的pid不在本节点时消息转发过去.
+	// 返回nil, nil表示激活器暂存了消息,稍后自己重新投递;不管理的名字返回ErrProcessNotExist
+	IActivator interface {
+		Activate(name string, message *Message) (*Pid, *Error)
 	}
 
 	IGroup interface {
@@ -98,6 +167,18 @@ type (
 		Dispatcher IActorDispatcher
 		Mailbox    IActorMailbox
 		Name       string
+		Supervisor ISupervisorStrategy
+		Parent     *Pid
+		// ReceiveTimeout 超过该时间没有收到消息通知actor,0不检测
+		ReceiveTimeout time.Duration
+		// StopOnReceiveTimeout 空闲超时后自动停止actor
+		StopOnReceiveTimeout bool
+		// Inbound 在系统拦截器之后执行
+		Inbound []InboundMiddleware
+		// Outbound 在系统拦截器之前执行
+		Outbound []OutboundMiddleware
+		// Priorities 方法优先级,未指定Mailbox时使用优先级邮箱
+		Priorities map[string]MessagePriority
 	}
 )
 
@@ -125,3 +206,47 @@ func WithActorName(name string) ProcessOption {
 		b.Name = name
 	}
 }
+
+// WithActorReceiveTimeout
+// @Description: 空闲超时,超时调用actor的OnReceiveTimeout,autoStop为true时停止actor
+// @param d
+// @param autoStop
+func WithActorReceiveTimeout(d time.Duration, autoStop bool) ProcessOption {
+	return func(b *ActorProcessOptions) {
+		b.ReceiveTimeout = d
+		b.StopOnReceiveTimeout = autoStop
+	}
+}
+
+// WithActorInbound
+// @Description: actor消息处理拦截器
+// @param middlewares
+func WithActorInbound(middlewares ...InboundMiddleware) ProcessOption {
+	return func(b *ActorProcessOptions) {
+		b.Inbound = append(b.Inbound, middlewares...)
+	}
+}
+
+// WithActorOutbound
+// @Description: actor发送消息拦截器
+// @param middlewares
+func WithActorOutbound(middlewares ...OutboundMiddleware) ProcessOption {
+	return func(b *ActorProcessOptions) {
+		b.Outbound = append(b.Outbound, middlewares...)
+	}
+}
+
+// WithActorPriorities
+// @Description: 按方法名设置消息优先级,使用优先级邮箱,与WithActorMailBox同时设置时以邮箱为准
+// @param priorities
+func WithActorPriorities(priorities map[string]MessagePriority) ProcessOption {
+	return func(b *ActorProcessOptions) {
+		b.Priorities = priorities
+	}
+}
+
+func WithActorSupervisor(supervisor ISupervisorStrategy) ProcessOption {
+	return func(b *ActorProcessOptions) {
+		b.Supervisor = supervisor
+	}
+}
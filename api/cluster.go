@@ -42,16 +42,21 @@ type (
 		GetByKind(kind string) (result []INodeBase)
 		GetAll() (result []INodeBase)
 		AddNode(node INodeBase) *Error
+		UpdateNode(node INodeBase) *Error
 		RemoveNode(nodeId string) *Error
+		AddTopologyHandler(handler TopologyHandler)
 	}
 	IDiscoveryProvider interface {
 		IModule
 		WatchNode(clusterName string, f EventNodeUpdateHandler) *Error
 		AddNode(node INodeBase) *Error
+		UpdateNode(node INodeBase) *Error
 		RemoveNode(nodeId string) *Error
 	}
 
 	EventNodeUpdateHandler func(waitIndex uint64, nodeDict map[uint64]*BaseNode)
+	// TopologyHandler 节点加入或离开时回调,在discovery的watch协程中执行
+	TopologyHandler func(topology *Topology)
 
 	ICluster interface {
 		IModule
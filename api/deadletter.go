@@ -0,0 +1,44 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: deadletter
+ * @Version: 1.0.0
+ * @Date: 2025/1/15 11:10
+ */
+
+package api
+
+// DeadLetterFuncName 死信组名,订阅者AddGroup(DeadLetterFuncName)并实现 OnDeadLetter(msg *api.DeadLetter)
+const DeadLetterFuncName = "OnDeadLetter"
+
+type (
+	// DeadLetter
+	// @Description: 无法投递或无法处理的消息
+	DeadLetter struct {
+		Message *Message
+		Reason  *Error
+		// Time 产生时间(毫秒)
+		Time int64
+	}
+
+	// IDeadLetter
+	// @Description: 死信收集,发布到死信组并统计数量
+	IDeadLetter interface {
+		Publish(message *Message, reason *Error)
+		Count() uint64
+		CountByReason(reason *Error) uint64
+	}
+)
+
+// IsInternalMethod
+// @Description: 框架内部消息,不进入死信
+func IsInternalMethod(method string) bool {
+	if IsSystemMethod(method) {
+		return true
+	}
+	switch method {
+	case ContinuationFuncName, TimerFuncName, ReceiveTimeoutFuncName, DeadLetterFuncName:
+		return true
+	}
+	return false
+}
@@ -36,6 +36,20 @@ func NewErr(str string, id uint16) *Error {
 	return err
 }
 
+// FindErr
+// @Description: 跨节点反序列化的错误还原为注册的错误对象,调用方可以直接用==比较
+// @param err
+// @return *Error 未注册的错误原样返回
+func FindErr(err *Error) *Error {
+	if err == nil {
+		return nil
+	}
+	if v, ok := idErrMap.Load(err.Id); ok {
+		return v.(*Error)
+	}
+	return err
+}
+
 var (
 	Ok                        = NewErr("正确", 0)
 	ErrMsgPackPack            = NewErr("msgpack打包错误", 1)
@@ -70,6 +84,30 @@ var (
 	ErrNatsRespond            = NewErr("nats respond err", 30)
 	ErrActorRouterIsNil       = NewErr("actor router is nil", 31)
 	ErrInvalidActorMessage    = NewErr("invalid actor message", 32)
+	ErrActorPanic             = NewErr("actor panic", 33)
+	ErrMailboxFull            = NewErr("mailbox is full", 34)
+	ErrCallCanceled           = NewErr("call canceled", 35)
+	ErrInvalidCronSpec        = NewErr("invalid cron spec", 36)
+	ErrActorBehaviorIsNil     = NewErr("actor behavior is nil", 37)
+	ErrActorStash             = NewErr("message can not stash", 38)
+	ErrActorInvoke            = NewErr("actor invoke error", 39)
+	ErrActorRequestType       = NewErr("actor request type mismatch", 40)
+	ErrActorReplyType         = NewErr("actor reply type mismatch", 41)
+	ErrJournalIsNil           = NewErr("journal is nil", 42)
+	ErrJournal                = NewErr("journal error", 43)
+	ErrJournalIndex           = NewErr("journal index not continuous", 44)
+	ErrPersistentEventType    = NewErr("persistent event type not registered", 45)
+	ErrPersistRecovering      = NewErr("can not persist while recovering", 46)
+	ErrGrainKindNotExist      = NewErr("grain kind not exist", 47)
+	ErrSingletonNotRunning    = NewErr("singleton not running", 48)
+	ErrSingletonBufferFull    = NewErr("singleton proxy buffer full", 49)
+	ErrGossip                 = NewErr("gossip err", 50)
+	ErrRpcNodeNotExist        = NewErr("rpc node not exist", 51)
+	ErrRpcConnClosed          = NewErr("rpc connection closed", 52)
+	ErrRpcTimeout             = NewErr("rpc timeout", 53)
+	ErrRpcTopic               = NewErr("rpc invalid topic", 54)
+	ErrGrainNotOwner          = NewErr("grain not owned by this node", 55)
+	ErrRpcFrameTooLarge       = NewErr("rpc frame too large", 56)
 )
 
 func IsOk(err *Error) bool {
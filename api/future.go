@@ -0,0 +1,23 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: future
+ * @Version: 1.0.0
+ * @Date: 2025/1/10 14:05
+ */
+
+package api
+
+type (
+	// IFuture
+	// @Description: 异步请求结果
+	IFuture interface {
+		// Wait 阻塞等待结果
+		Wait() *RespondMessage
+		// OnComplete 结果返回后回调,在完成结果的协程中执行
+		OnComplete(f func(rsp *RespondMessage))
+	}
+	// AsyncCallback
+	// @Description: CallAsync回调,在调用者actor的邮箱中执行
+	AsyncCallback func(reply interface{}, err *Error)
+)
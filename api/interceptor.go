@@ -0,0 +1,41 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: interceptor
+ * @Version: 1.0.0
+ * @Date: 2025/1/16 10:30
+ */
+
+package api
+
+import "github.com/dingqinghui/gas/extend/reflectx"
+
+type (
+	// InboundHandler
+	// @Description: 调用actor的消息处理函数,method为路由找到的方法,返回处理结果
+	InboundHandler func(ctx IActorContext, msg *Message, method *reflectx.Method) *RespondMessage
+	// InboundMiddleware
+	// @Description: 消息处理拦截器,不调用next则拦截消息
+	InboundMiddleware func(next InboundHandler) InboundHandler
+
+	// OutboundHandler
+	// @Description: 投递消息,本地投递到邮箱,远程通过rpc发送
+	OutboundHandler func(msg *Message) *Error
+	// OutboundMiddleware
+	// @Description: 消息发送拦截器,不调用next则消息不发送
+	OutboundMiddleware func(next OutboundHandler) OutboundHandler
+)
+
+func ChainInbound(middlewares []InboundMiddleware, handler InboundHandler) InboundHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func ChainOutbound(middlewares []OutboundMiddleware, handler OutboundHandler) OutboundHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,81 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: load
+ * @Version: 1.0.0
+ * @Date: 2025/1/23 10:05
+ */
+
+package api
+
+import (
+	"github.com/duke-git/lancet/v2/convertor"
+	"strconv"
+)
+
+// 节点负载在discovery meta中的key,consul的meta key只允许字母数字下划线
+const (
+	MetaLoadActors      = "load_actors"
+	MetaLoadMailbox     = "load_mailbox"
+	MetaLoadConnections = "load_connections"
+	MetaLoadGoroutines  = "load_goroutines"
+)
+
+type (
+	// NodeLoad
+	// @Description: 节点负载,由节点定时发布到discovery
+	NodeLoad struct {
+		Actors      int64 // actor数量
+		Mailbox     int64 // 所有邮箱积压的消息数量
+		Connections int64 // 网络连接数量
+		Goroutines  int64 // 协程池中执行的任务数量
+	}
+
+	// LoadScorer 负载评分,分数越低负载越低
+	LoadScorer func(load *NodeLoad) int64
+)
+
+// DefaultLoadScorer
+// @Description: 各项负载直接相加,邮箱积压说明处理不过来,权重加倍
+func DefaultLoadScorer(load *NodeLoad) int64 {
+	if load == nil {
+		return 0
+	}
+	return load.Actors + 2*load.Mailbox + load.Connections + load.Goroutines
+}
+
+// WriteMeta
+// @Description: 写入meta
+// @receiver l
+// @param meta
+func (l *NodeLoad) WriteMeta(meta map[string]string) {
+	meta[MetaLoadActors] = convertor.ToString(l.Actors)
+	meta[MetaLoadMailbox] = convertor.ToString(l.Mailbox)
+	meta[MetaLoadConnections] = convertor.ToString(l.Connections)
+	meta[MetaLoadGoroutines] = convertor.ToString(l.Goroutines)
+}
+
+// GetNodeLoad
+// @Description: 从节点meta中读取负载,没有发布负载返回nil
+// @param node
+// @return *NodeLoad
+func GetNodeLoad(node INodeBase) *NodeLoad {
+	if node == nil {
+		return nil
+	}
+	meta := node.GetMeta()
+	if _, ok := meta[MetaLoadActors]; !ok {
+		return nil
+	}
+	return &NodeLoad{
+		Actors:      parseLoad(meta[MetaLoadActors]),
+		Mailbox:     parseLoad(meta[MetaLoadMailbox]),
+		Connections: parseLoad(meta[MetaLoadConnections]),
+		Goroutines:  parseLoad(meta[MetaLoadGoroutines]),
+	}
+}
+
+func parseLoad(v string) int64 {
+	n, _ := strconv.ParseInt(v, 10, 64)
+	return n
+}
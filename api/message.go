@@ -8,6 +8,8 @@
 
 package api
 
+import "time"
+
 type MessageEnum int32
 
 const (
@@ -17,11 +19,35 @@ const (
 )
 
 const (
-	InitFuncName = "OnInit"
-	StopFuncName = "OnStop"
+	InitFuncName       = "OnInit"
+	StopFuncName       = "OnStop"
+	RestartFuncName    = "OnRestart"
+	FailureFuncName    = "OnFailure"
+	WatchFuncName      = "OnWatch"
+	UnwatchFuncName    = "OnUnwatch"
+	TerminatedFuncName = "OnTerminated"
+	// ContinuationFuncName 异步调用结果回到调用者邮箱
+	ContinuationFuncName = "OnContinuation"
+	// TimerFuncName actor定时器触发
+	TimerFuncName = "OnTimer"
+	// ReceiveTimeoutFuncName actor空闲超时,actor可实现同名方法处理
+	ReceiveTimeoutFuncName = "OnReceiveTimeout"
+)
+
+const (
+	TerminatedReasonStopped  TerminatedReason = 0
+	TerminatedReasonNotExist TerminatedReason = 1
+	TerminatedReasonNodeLeft TerminatedReason = 2
 )
 
 type (
+	TerminatedReason int32
+	// Terminated
+	// @Description: 被监视的actor停止后,投递给监视者 OnTerminated(msg *api.Terminated)
+	Terminated struct {
+		Who    *Pid
+		Reason TerminatedReason
+	}
 	Message struct {
 		Typ     MessageEnum
 		Method  string
@@ -29,7 +55,11 @@ type (
 		To      *Pid
 		Data    []byte
 		Session *Session
-		respond RespondFun
+		// Timeout 剩余超时时间(纳秒),跨节点传递时由deadline换算
+		Timeout  int64
+		respond  RespondFun
+		body     interface{}
+		deadline time.Time
 	}
 	RespondMessage struct {
 		Data []byte
@@ -38,6 +68,27 @@ type (
 	RespondFun func(rsp *RespondMessage) *Error
 )
 
+// IsSystemMethod
+// @Description: 生命周期、监督、监视等系统消息,走邮箱系统通道
+func IsSystemMethod(method string) bool {
+	switch method {
+	case InitFuncName, StopFuncName, RestartFuncName, FailureFuncName,
+		WatchFuncName, UnwatchFuncName, TerminatedFuncName:
+		return true
+	}
+	return false
+}
+
+// IsSystemLaneMethod
+// @Description: 走邮箱系统通道的消息,除系统消息外还有定时器、异步回调和空闲超时,不受有界邮箱容量限制
+func IsSystemLaneMethod(method string) bool {
+	switch method {
+	case ContinuationFuncName, TimerFuncName, ReceiveTimeoutFuncName:
+		return true
+	}
+	return IsSystemMethod(method)
+}
+
 func (m *Message) Respond(rsp *RespondMessage) *Error {
 	if m.respond == nil {
 		return nil
@@ -45,13 +96,89 @@ func (m *Message) Respond(rsp *RespondMessage) *Error {
 	return m.respond(rsp)
 }
 
+// NeedRespond
+// @Description: 调用方在等待回复
+func (m *Message) NeedRespond() bool {
+	return m.respond != nil
+}
+
+// Responder
+// @Description: 调用方的回复函数,转发时包装后再设置回来
+func (m *Message) Responder() RespondFun {
+	return m.respond
+}
+
 func (m *Message) SetRespond(respond RespondFun) {
 	m.respond = respond
 }
+
+// Body
+// @Description: 本地消息携带的对象,不参与序列化
+func (m *Message) Body() interface{} {
+	return m.body
+}
+
+func (m *Message) SetBody(body interface{}) {
+	m.body = body
+}
+
+func (m *Message) Deadline() time.Time {
+	return m.deadline
+}
+
+func (m *Message) SetDeadline(deadline time.Time) {
+	m.deadline = deadline
+}
+
+// Expired
+// @Description: 调用方已放弃等待
+func (m *Message) Expired() bool {
+	return !m.deadline.IsZero() && time.Now().After(m.deadline)
+}
+
+// EncodeDeadline
+// @Description: 跨节点发送前,将deadline换算为剩余时间
+func (m *Message) EncodeDeadline() {
+	if m.deadline.IsZero() {
+		m.Timeout = 0
+		return
+	}
+	remaining := time.Until(m.deadline)
+	if remaining <= 0 {
+		remaining = 1
+	}
+	m.Timeout = int64(remaining)
+}
+
+// DecodeDeadline
+// @Description: 收到跨节点消息后,按本地时钟还原deadline
+func (m *Message) DecodeDeadline() {
+	if m.Timeout <= 0 {
+		return
+	}
+	m.deadline = time.Now().Add(time.Duration(m.Timeout))
+}
+
 func (m *Message) IsBroadcast() bool {
 	return m.Typ == MessageEnumBroadcast
 }
 
+// DecodeArg
+// @Description: 反序列化消息参数,data为空时不处理,供代码生成的分发函数使用
+// @param serializer
+// @param data
+// @param arg
+// @return *Error
+func DecodeArg(serializer ISerializer, data []byte, arg interface{}) *Error {
+	if data == nil || serializer == nil {
+		return nil
+	}
+	if err := serializer.Unmarshal(data, arg); err != nil {
+		return ErrUnmarshal
+	}
+	return nil
+}
+
 func BuildInnerMessage(from, to *Pid, methodName string, data []byte) *Message {
 	return &Message{
 		From:   from,
@@ -31,6 +31,7 @@ type (
 		Ref(c gnet.Conn) INetEntity
 		Unlink(c gnet.Conn)
 		Typ() NetEntityType
+		ConnectionCount() int
 	}
 
 	INetRouter interface {
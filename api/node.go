@@ -9,22 +9,11 @@
 package api
 
 import (
-	"github.com/dingqinghui/gas/extend/xerror"
 	"github.com/spf13/viper"
 )
 
-var (
-	currentNode INode
-)
-
-func SetNode(node INode) {
-	currentNode = node
-}
-
-func GetNode() INode {
-	xerror.NilAssert(currentNode)
-	return currentNode
-}
+// MetaStartTime 节点启动时间(unix纳秒),用于选出集群中最老的节点
+const MetaStartTime = "start_time"
 
 type (
 	INodeBase interface {
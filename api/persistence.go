@@ -0,0 +1,41 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: persistence
+ * @Version: 1.0.0
+ * @Date: 2025/1/20 10:05
+ */
+
+package api
+
+type (
+	// PersistentEvent
+	// @Description: 日志中的事件,Index从1开始连续递增
+	PersistentEvent struct {
+		Index uint64
+		Type  string
+		Data  []byte
+	}
+
+	// PersistentSnapshot
+	// @Description: Index及之前的事件合并后的状态
+	PersistentSnapshot struct {
+		Index uint64
+		Type  string
+		Data  []byte
+	}
+
+	// IJournal
+	// @Description: 事件和快照存储
+	IJournal interface {
+		// Append 追加事件,Index必须紧接已有的最后一个事件
+		Append(persistenceId string, event *PersistentEvent) *Error
+		// Replay 按顺序回放Index>=fromIndex的事件
+		Replay(persistenceId string, fromIndex uint64, f func(event *PersistentEvent) *Error) *Error
+		// DeleteTo 删除Index<=toIndex的事件
+		DeleteTo(persistenceId string, toIndex uint64) *Error
+		SaveSnapshot(persistenceId string, snapshot *PersistentSnapshot) *Error
+		// LoadSnapshot 没有快照返回nil
+		LoadSnapshot(persistenceId string) (*PersistentSnapshot, *Error)
+	}
+)
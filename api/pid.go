@@ -8,6 +8,8 @@
 
 package api
 
+import "fmt"
+
 type Pid struct {
 	NodeId uint64
 	UniqId uint64
@@ -35,6 +37,34 @@ func (x *Pid) GetName() string {
 	return ""
 }
 
+func (x *Pid) String() string {
+	if x == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%s", x.NodeId, x.UniqId, x.Name)
+}
+
+// Key
+// @Description: actor的唯一标识,有UniqId时名字可变不参与,按名字寻址的pid用NodeId+Name
+// @receiver x
+// @return string
+func (x *Pid) Key() string {
+	if x == nil {
+		return ""
+	}
+	if x.UniqId > 0 {
+		return fmt.Sprintf("%d.%d", x.NodeId, x.UniqId)
+	}
+	return fmt.Sprintf("%d.0.%s", x.NodeId, x.Name)
+}
+
+func (x *Pid) Equal(other *Pid) bool {
+	if x == nil || other == nil {
+		return x == other
+	}
+	return x.NodeId == other.NodeId && x.UniqId == other.UniqId && x.Name == other.Name
+}
+
 func ValidPid(pid *Pid) bool {
 	if pid == nil {
 		return false
@@ -17,8 +17,9 @@ const (
 	sessionClose = "Close"
 )
 
-func NewSession(entity INetEntity) *Session {
+func NewSession(node INode, entity INetEntity) *Session {
 	return &Session{
+		node:   node,
 		entity: entity,
 		ctx:    nil,
 	}
@@ -30,17 +31,38 @@ type Session struct {
 	Index  uint32
 	ctx    IActorContext
 	entity INetEntity
+	node   INode
 }
 
+// SetContext
+// @Description: 设置处理消息的actor,远程节点传来的session通过actor找到所在节点
+// @receiver s
+// @param ctx
 func (s *Session) SetContext(ctx IActorContext) {
 	s.ctx = ctx
+	if ctx != nil && ctx.System() != nil {
+		s.node = ctx.System().Node()
+	}
+}
+
+// Node
+// @Description: session所在节点
+func (s *Session) Node() INode {
+	return s.node
+}
+
+func (s *Session) serializer() ISerializer {
+	if s.node == nil {
+		return nil
+	}
+	return s.node.Serializer()
 }
 
 func (s *Session) Response(payload interface{}) *Error {
-	if GetNode() == nil || GetNode().Serializer() == nil {
+	if s.serializer() == nil {
 		return nil
 	}
-	body, err := GetNode().Serializer().Marshal(payload)
+	body, err := s.serializer().Marshal(payload)
 	if err != nil {
 		return ErrMarshal
 	}
@@ -58,10 +80,10 @@ func (s *Session) ResponseErr(err *Error) *Error {
 }
 
 func (s *Session) Push(mid uint16, payload interface{}) *Error {
-	if GetNode() == nil || GetNode().Serializer() == nil {
+	if s.serializer() == nil {
 		return nil
 	}
-	body, err := GetNode().Serializer().Marshal(payload)
+	body, err := s.serializer().Marshal(payload)
 	if err != nil {
 		return ErrMarshal
 	}
@@ -81,10 +103,10 @@ func (s *Session) push(m *message.Message) *Error {
 }
 
 func (s *Session) forwardToAgent(method string, payload interface{}) *Error {
-	if GetNode() == nil || GetNode().Serializer() == nil {
+	if s.serializer() == nil {
 		return nil
 	}
-	data, err := GetNode().Serializer().Marshal(payload)
+	data, err := s.serializer().Marshal(payload)
 	if err != nil {
 		return ErrMarshal
 	}
@@ -96,7 +118,7 @@ func (s *Session) forwardToAgent(method string, payload interface{}) *Error {
 		To:      s.Agent,
 		From:    s.ctx.Self(),
 	}
-	return GetNode().System().PostMessage(s.Agent, actMessage)
+	return s.node.System().PostMessage(s.Agent, actMessage)
 }
 
 func (s *Session) Close(reason *Error) *Error {
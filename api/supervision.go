@@ -0,0 +1,104 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: supervision
+ * @Version: 1.0.0
+ * @Date: 2025/1/6 10:12
+ */
+
+package api
+
+import (
+	"time"
+)
+
+const (
+	ResumeDirective Directive = iota
+	RestartDirective
+	StopDirective
+	EscalateDirective
+)
+
+type (
+	Directive   int
+	DeciderFunc func(reason interface{}) Directive
+
+	// ISupervisor
+	// @Description: 监督者,可以是父actor,也可以是actor system
+	ISupervisor interface {
+		Children() []*Pid
+		EscalateFailure(reason interface{}, message interface{})
+		RestartChildren(pids ...*Pid)
+		StopChildren(pids ...*Pid)
+		ResumeChildren(pids ...*Pid)
+	}
+
+	// ISupervisorStrategy
+	// @Description: 子actor崩溃后的处理策略
+	ISupervisorStrategy interface {
+		HandleFailure(supervisor ISupervisor, child *Pid, rs *RestartStatistics, reason interface{}, message interface{})
+	}
+
+	RestartStatistics struct {
+		failureTimes []time.Time
+	}
+)
+
+func (d Directive) String() string {
+	switch d {
+	case ResumeDirective:
+		return "resume"
+	case RestartDirective:
+		return "restart"
+	case StopDirective:
+		return "stop"
+	case EscalateDirective:
+		return "escalate"
+	}
+	return "unknown"
+}
+
+// DefaultDecider
+// @Description: 默认崩溃即重启
+func DefaultDecider(_ interface{}) Directive {
+	return RestartDirective
+}
+
+func NewRestartStatistics() *RestartStatistics {
+	return &RestartStatistics{}
+}
+
+func (rs *RestartStatistics) FailureCount() int {
+	return len(rs.failureTimes)
+}
+
+func (rs *RestartStatistics) Fail() {
+	rs.failureTimes = append(rs.failureTimes, time.Now())
+}
+
+func (rs *RestartStatistics) Reset() {
+	rs.failureTimes = rs.failureTimes[:0]
+}
+
+// NumberOfFailures
+// @Description: within时间内的崩溃次数,窗口外的记录直接丢弃,避免记录无限增长
+// @receiver rs
+// @param within
+// @return int
+func (rs *RestartStatistics) NumberOfFailures(within time.Duration) int {
+	if within <= 0 {
+		return len(rs.failureTimes)
+	}
+	now := time.Now()
+	expired := 0
+	for _, t := range rs.failureTimes {
+		if now.Sub(t) < within {
+			break
+		}
+		expired++
+	}
+	if expired > 0 {
+		rs.failureTimes = append(rs.failureTimes[:0], rs.failureTimes[expired:]...)
+	}
+	return len(rs.failureTimes)
+}
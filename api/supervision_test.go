@@ -0,0 +1,32 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: supervision_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/6 10:12
+ */
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestartStatisticsPrune(t *testing.T) {
+	rs := NewRestartStatistics()
+	old := time.Now().Add(-time.Minute)
+	for i := 0; i < 100; i++ {
+		rs.failureTimes = append(rs.failureTimes, old)
+	}
+	rs.Fail()
+	if n := rs.NumberOfFailures(10 * time.Second); n != 1 {
+		t.Fatalf("failures in window = %d, want 1", n)
+	}
+	if n := rs.FailureCount(); n != 1 {
+		t.Fatalf("expired failures kept, count = %d", n)
+	}
+	if n := rs.NumberOfFailures(0); n != 1 {
+		t.Fatalf("failures without window = %d, want 1", n)
+	}
+}
@@ -0,0 +1,13 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: timer
+ * @Version: 1.0.0
+ * @Date: 2025/1/13 14:20
+ */
+
+package api
+
+// TimerFunc
+// @Description: actor定时器回调,在actor邮箱中执行
+type TimerFunc func(timerId uint64, payload interface{})
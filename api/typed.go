@@ -0,0 +1,205 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: typed
+ * @Version: 1.0.0
+ * @Date: 2025/1/18 10:15
+ */
+
+package api
+
+import (
+	"github.com/dingqinghui/gas/extend/reflectx"
+	"reflect"
+	"sync"
+)
+
+var typeOfEmpty = reflect.TypeOf(struct{}{})
+
+// methodCheckCache 本地actor方法签名的校验结果
+var methodCheckCache sync.Map
+
+type methodCheckKey struct {
+	router IActorRouter
+	method string
+	req    reflect.Type
+	rsp    reflect.Type
+}
+
+// Call
+// @Description: 泛型同步调用,目标actor在本地时先校验方法签名
+// @param ctx 调用者
+// @param to
+// @param method
+// @param req
+// @return Rsp
+// @return *Error
+func Call[Req, Rsp any](ctx IActorContext, to *Pid, method string, req Req) (Rsp, *Error) {
+	var rsp Rsp
+	if err := checkLocal[Req, Rsp](ctx.System(), to, method, true); err != nil {
+		return rsp, err
+	}
+	if err := ctx.Call(to, method, req, &rsp); err != nil {
+		return rsp, err
+	}
+	return rsp, nil
+}
+
+// Send
+// @Description: 泛型异步发送,目标actor在本地时先校验方法参数
+func Send[Req any](ctx IActorContext, to *Pid, method string, req Req) *Error {
+	if err := checkLocal[Req, struct{}](ctx.System(), to, method, false); err != nil {
+		return err
+	}
+	return ctx.Send(to, method, req)
+}
+
+// MethodRef
+// @Description: 类型化的actor方法,创建时校验actor的方法签名
+type MethodRef[Req, Rsp any] struct {
+	name string
+}
+
+// NewMethodRef
+// @Description: 按actor原型校验方法签名,Rsp为struct{}表示方法没有返回值
+// @param system
+// @param prototype actor原型,只用于获取路由
+// @param method
+// @return *MethodRef[Req, Rsp]
+// @return *Error
+func NewMethodRef[Req, Rsp any](system IActorSystem, prototype IActor, method string) (*MethodRef[Req, Rsp], *Error) {
+	if err := checkMethod[Req, Rsp](system.GetOrSetRouter(prototype), method, true); err != nil {
+		return nil, err
+	}
+	return &MethodRef[Req, Rsp]{name: method}, nil
+}
+
+func (m *MethodRef[Req, Rsp]) Name() string {
+	return m.name
+}
+
+func (m *MethodRef[Req, Rsp]) Call(ctx IActorContext, to *Pid, req Req) (Rsp, *Error) {
+	var rsp Rsp
+	if err := ctx.Call(to, m.name, req, &rsp); err != nil {
+		return rsp, err
+	}
+	return rsp, nil
+}
+
+func (m *MethodRef[Req, Rsp]) Send(ctx IActorContext, to *Pid, req Req) *Error {
+	return ctx.Send(to, m.name, req)
+}
+
+// TypedGroup
+// @Description: 类型化的广播组,组名即为成员处理消息的方法名
+type TypedGroup[Msg any] struct {
+	name string
+}
+
+func NewTypedGroup[Msg any](name string) *TypedGroup[Msg] {
+	return &TypedGroup[Msg]{name: name}
+}
+
+func (g *TypedGroup[Msg]) Name() string {
+	return g.name
+}
+
+// Join
+// @Description: 加入组,校验actor有同名方法并且参数类型为Msg
+// @receiver g
+// @param ctx
+// @return *Error
+func (g *TypedGroup[Msg]) Join(ctx IActorContext) *Error {
+	if err := checkMethod[Msg, struct{}](ctx.Router(), g.name, false); err != nil {
+		return err
+	}
+	ctx.AddGroup(g.name)
+	return nil
+}
+
+func (g *TypedGroup[Msg]) Leave(ctx IActorContext) {
+	ctx.RemoveGroup(g.name)
+}
+
+func (g *TypedGroup[Msg]) Broadcast(ctx IActorContext, msg Msg) *Error {
+	return ctx.BroadcastGroup(g.name, msg)
+}
+
+func checkLocal[Req, Rsp any](system IActorSystem, to *Pid, method string, checkReply bool) *Error {
+	if system == nil || !ValidPid(to) || !system.IsLocalPid(to) {
+		return nil
+	}
+	process := system.Find(to)
+	if process == nil {
+		return nil
+	}
+	// 当前处理对象由目标actor的协程切换,只按actor类型的路由校验
+	router := process.Context().BaseRouter()
+	if router == nil || router.Get(method) == nil {
+		// Become后的处理对象可能有该方法,交给目标actor处理
+		return nil
+	}
+	return checkMethod[Req, Rsp](router, method, checkReply)
+}
+
+// checkMethod
+// @Description: 校验路由方法的参数和返回值类型,代码生成的方法不校验
+func checkMethod[Req, Rsp any](router IActorRouter, method string, checkReply bool) *Error {
+	if router == nil {
+		return ErrActorRouterIsNil
+	}
+	key := methodCheckKey{
+		router: router,
+		method: method,
+		req:    reflect.TypeOf((*Req)(nil)).Elem(),
+		rsp:    reflect.TypeOf((*Rsp)(nil)).Elem(),
+	}
+	if v, ok := methodCheckCache.Load(key); ok {
+		err, _ := v.(*Error)
+		return err
+	}
+	err := checkSignature(router.Get(method), key.req, key.rsp, checkReply)
+	methodCheckCache.Store(key, err)
+	return err
+}
+
+func checkSignature(md *reflectx.Method, req, rsp reflect.Type, checkReply bool) *Error {
+	if md == nil {
+		return ErrActorNotMethod
+	}
+	if md.Invoke != nil || md.Typ == nil {
+		return nil
+	}
+	switch md.ArgNum {
+	case 1:
+	case 2:
+		if !compatible(md.ArgTypes[1], req) {
+			return ErrActorRequestType
+		}
+	default:
+		return ErrActorMethodArgNum
+	}
+	if !checkReply {
+		return nil
+	}
+	if md.Typ.NumOut() == 2 {
+		if !compatible(md.Typ.Out(0), rsp) {
+			return ErrActorReplyType
+		}
+	} else if rsp != typeOfEmpty {
+		return ErrActorReplyType
+	}
+	return nil
+}
+
+// compatible
+// @Description: 序列化后可以互相转换,T和*T视为相同
+func compatible(expect, actual reflect.Type) bool {
+	if expect == actual {
+		return true
+	}
+	if expect.Kind() == reflect.Ptr && expect.Elem() == actual {
+		return true
+	}
+	return actual.Kind() == reflect.Ptr && actual.Elem() == expect
+}
@@ -0,0 +1,161 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: consistent_hash
+ * @Version: 1.0.0
+ * @Date: 2025/1/22 10:20
+ */
+
+package balancer
+
+import (
+	"fmt"
+	"github.com/dingqinghui/gas/api"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultReplicas = 100
+
+// NewConsistentHash
+// @Description: 一致性哈希,相同user总是落到同一节点,节点变化只迁移最少的key
+// @param discovery 节点变化时清理缓存的哈希环,为nil时在节点集合变化后重建
+// @param replicas 每个节点的虚拟节点数,<=0使用默认值
+func NewConsistentHash(discovery api.IDiscovery, replicas int) *consistentHash {
+	if replicas <= 0 {
+		replicas = defaultReplicas
+	}
+	b := &consistentHash{
+		replicas: replicas,
+	}
+	if discovery != nil {
+		discovery.AddTopologyHandler(b.UpdateTopology)
+	}
+	return b
+}
+
+type consistentHash struct {
+	sync.RWMutex
+	replicas int
+	// current 只缓存最近一次使用的节点集合的哈希环,节点集合变化时重建
+	current *hashRing
+}
+
+// Do
+// @Description: user为nil时随机选择
+// @receiver b
+// @param nodeArray
+// @param user 一般是玩家id、房间id
+// @return api.INodeBase
+func (b *consistentHash) Do(nodeArray []api.INodeBase, user interface{}) api.INodeBase {
+	if len(nodeArray) <= 0 {
+		return nil
+	}
+	if user == nil {
+		return nodeArray[rand.Intn(len(nodeArray))]
+	}
+	ring := b.ring(nodeArray)
+	return ring.get(hashKey(user))
+}
+
+// UpdateTopology
+// @Description: 集群节点变化,丢弃旧的哈希环,下次选择时重建
+// @receiver b
+// @param _
+func (b *consistentHash) UpdateTopology(_ *api.Topology) {
+	b.Lock()
+	defer b.Unlock()
+	b.current = nil
+}
+
+func (b *consistentHash) ring(nodeArray []api.INodeBase) *hashRing {
+	ids := make([]string, 0, len(nodeArray))
+	for _, node := range nodeArray {
+		ids = append(ids, strconv.FormatUint(node.GetID(), 10))
+	}
+	sort.Strings(ids)
+	signature := strings.Join(ids, ",")
+
+	b.RLock()
+	ring := b.current
+	b.RUnlock()
+	if ring != nil && ring.signature == signature {
+		return ring
+	}
+	ring = newHashRing(signature, nodeArray, b.replicas)
+	b.Lock()
+	b.current = ring
+	b.Unlock()
+	return ring
+}
+
+type hashRing struct {
+	// signature 排序后的节点id
+	signature string
+	hashes    []uint32
+	nodes     map[uint32]api.INodeBase
+}
+
+func newHashRing(signature string, nodeArray []api.INodeBase, replicas int) *hashRing {
+	ring := &hashRing{
+		signature: signature,
+		hashes:    make([]uint32, 0, len(nodeArray)*replicas),
+		nodes:     make(map[uint32]api.INodeBase, len(nodeArray)*replicas),
+	}
+	for _, node := range nodeArray {
+		id := strconv.FormatUint(node.GetID(), 10)
+		for i := 0; i < replicas; i++ {
+			hash := hash32([]byte(id + "#" + strconv.Itoa(i)))
+			// 哈希冲突时保留id较小的节点,保证结果与节点顺序无关
+			if exist, ok := ring.nodes[hash]; ok {
+				if exist.GetID() < node.GetID() {
+					continue
+				}
+			} else {
+				ring.hashes = append(ring.hashes, hash)
+			}
+			ring.nodes[hash] = node
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	return ring
+}
+
+func (r *hashRing) get(hash uint32) api.INodeBase {
+	index := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	if index == len(r.hashes) {
+		index = 0
+	}
+	return r.nodes[r.hashes[index]]
+}
+
+func hashKey(user interface{}) uint32 {
+	var key string
+	switch v := user.(type) {
+	case string:
+		key = v
+	case []byte:
+		return hash32(v)
+	case fmt.Stringer:
+		key = v.String()
+	default:
+		key = fmt.Sprint(v)
+	}
+	return hash32([]byte(key))
+}
+
+// hash32
+// @Description: fnv64a后做一次混淆,短key在环上分布更均匀
+func hash32(data []byte) uint32 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	v := h.Sum64()
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	return uint32(v)
+}
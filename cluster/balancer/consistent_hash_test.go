@@ -0,0 +1,95 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: consistent_hash_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/22 15:30
+ */
+
+package balancer
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+func newNodes(n int) []api.INodeBase {
+	var nodes []api.INodeBase
+	for i := 1; i <= n; i++ {
+		nodes = append(nodes, &api.BaseNode{Id: uint64(i)})
+	}
+	return nodes
+}
+
+func TestConsistentHash(t *testing.T) {
+	nodes := newNodes(5)
+	b := NewConsistentHash(nil, 0)
+	if b.Do(nil, "user") != nil {
+		t.Fatal("empty nodes selected a node")
+	}
+	reversed := make([]api.INodeBase, 0, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		reversed = append(reversed, nodes[i])
+	}
+	counts := make(map[uint64]int)
+	for k := 0; k < 10000; k++ {
+		user := "user" + strconv.Itoa(k)
+		node := b.Do(nodes, user)
+		// 结果与节点顺序无关
+		if b.Do(reversed, user).GetID() != node.GetID() {
+			t.Fatalf("%s selected different nodes", user)
+		}
+		counts[node.GetID()]++
+	}
+	for id, count := range counts {
+		if count < 1000 || count > 3000 {
+			t.Fatalf("node %d got %d of 10000 keys", id, count)
+		}
+	}
+	if hashKey(42) != hashKey("42") {
+		t.Fatal("int key hashed differently from its string form")
+	}
+}
+
+func TestConsistentHashRemove(t *testing.T) {
+	nodes := newNodes(5)
+	b := NewConsistentHash(nil, 0)
+	moved := 0
+	for k := 0; k < 10000; k++ {
+		user := "user" + strconv.Itoa(k)
+		before, after := b.Do(nodes, user).GetID(), b.Do(nodes[:4], user).GetID()
+		if before == after {
+			continue
+		}
+		// 只有移除节点上的key迁移
+		if before != 5 {
+			t.Fatalf("%s moved from node %d to %d", user, before, after)
+		}
+		moved++
+	}
+	if moved == 0 || moved > 3000 {
+		t.Fatalf("moved %d of 10000 keys", moved)
+	}
+}
+
+func TestConsistentHashTopology(t *testing.T) {
+	nodes := newNodes(3)
+	b := NewConsistentHash(nil, 10)
+	b.Do(nodes, "user")
+	ring := b.current
+	b.Do(nodes, "other")
+	if b.current != ring {
+		t.Fatal("ring rebuilt for the same nodes")
+	}
+	// 节点集合变化只保留新的哈希环
+	b.Do(nodes[:2], "user")
+	if b.current == ring || b.current.signature != "1,2" {
+		t.Fatalf("ring = %v", b.current.signature)
+	}
+	b.UpdateTopology(nil)
+	if b.current != nil {
+		t.Fatal("ring not dropped after topology update")
+	}
+}
@@ -0,0 +1,46 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: least_load
+ * @Version: 1.0.0
+ * @Date: 2025/1/23 11:10
+ */
+
+package balancer
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"math/rand"
+)
+
+// NewLeastLoad
+// @Description: 选择负载最低的节点,负载相同随机选择,未发布负载的节点视为空闲
+// @param scorer 为nil使用api.DefaultLoadScorer
+func NewLeastLoad(scorer api.LoadScorer) *leastLoad {
+	if scorer == nil {
+		scorer = api.DefaultLoadScorer
+	}
+	return &leastLoad{scorer: scorer}
+}
+
+type leastLoad struct {
+	scorer api.LoadScorer
+}
+
+func (b *leastLoad) Do(nodeArray []api.INodeBase, user interface{}) api.INodeBase {
+	if len(nodeArray) <= 0 {
+		return nil
+	}
+	var candidates []api.INodeBase
+	var minScore int64
+	for _, node := range nodeArray {
+		score := b.scorer(api.GetNodeLoad(node))
+		if len(candidates) == 0 || score < minScore {
+			minScore = score
+			candidates = append(candidates[:0], node)
+		} else if score == minScore {
+			candidates = append(candidates, node)
+		}
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
@@ -0,0 +1,68 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: least_load_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/23 15:40
+ */
+
+package balancer
+
+import (
+	"testing"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+func newLoadNode(id uint64, actors int64) api.INodeBase {
+	node := &api.BaseNode{Id: id, Meta: make(map[string]string)}
+	(&api.NodeLoad{Actors: actors}).WriteMeta(node.Meta)
+	return node
+}
+
+func TestLeastLoad(t *testing.T) {
+	b := NewLeastLoad(nil)
+	if b.Do(nil, nil) != nil {
+		t.Fatal("empty nodes selected a node")
+	}
+	nodes := []api.INodeBase{newLoadNode(1, 100), newLoadNode(2, 10), newLoadNode(3, 1000)}
+	for i := 0; i < 100; i++ {
+		if id := b.Do(nodes, nil).GetID(); id != 2 {
+			t.Fatalf("selected node %d", id)
+		}
+	}
+	// 未发布负载的节点视为空闲
+	nodes = append(nodes, &api.BaseNode{Id: 4})
+	if id := b.Do(nodes, nil).GetID(); id != 4 {
+		t.Fatalf("selected node %d, want idle node", id)
+	}
+	// 负载相同随机选择
+	equal := []api.INodeBase{newLoadNode(1, 10), newLoadNode(2, 10)}
+	counts := make(map[uint64]int)
+	for i := 0; i < 1000; i++ {
+		counts[b.Do(equal, nil).GetID()]++
+	}
+	if counts[1] == 0 || counts[2] == 0 {
+		t.Fatalf("equal load counts = %v", counts)
+	}
+	custom := NewLeastLoad(func(load *api.NodeLoad) int64 { return -load.Actors })
+	if id := custom.Do(nodes[:3], nil).GetID(); id != 3 {
+		t.Fatalf("custom scorer selected node %d", id)
+	}
+}
+
+func TestWeightedRandom(t *testing.T) {
+	b := NewWeightedRandom(nil)
+	if b.Do(nil, nil) != nil {
+		t.Fatal("empty nodes selected a node")
+	}
+	nodes := []api.INodeBase{newLoadNode(1, 100), newLoadNode(2, 10), newLoadNode(3, 1000)}
+	counts := make(map[uint64]int)
+	for i := 0; i < 10000; i++ {
+		counts[b.Do(nodes, nil).GetID()]++
+	}
+	// 负载越低被选中越多,但高负载节点也有机会
+	if !(counts[2] > counts[1] && counts[1] > counts[3] && counts[3] > 0) {
+		t.Fatalf("counts = %v", counts)
+	}
+}
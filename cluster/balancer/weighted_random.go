@@ -0,0 +1,49 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: weighted_random
+ * @Version: 1.0.0
+ * @Date: 2025/1/23 11:25
+ */
+
+package balancer
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"math/rand"
+)
+
+// NewWeightedRandom
+// @Description: 按负载加权随机,负载越低被选中概率越大,避免负载上报间隔内所有请求涌向同一节点
+// @param scorer 为nil使用api.DefaultLoadScorer
+func NewWeightedRandom(scorer api.LoadScorer) *weightedRandom {
+	if scorer == nil {
+		scorer = api.DefaultLoadScorer
+	}
+	return &weightedRandom{scorer: scorer}
+}
+
+type weightedRandom struct {
+	scorer api.LoadScorer
+}
+
+func (b *weightedRandom) Do(nodeArray []api.INodeBase, user interface{}) api.INodeBase {
+	if len(nodeArray) <= 0 {
+		return nil
+	}
+	weights := make([]float64, len(nodeArray))
+	var total float64
+	for i, node := range nodeArray {
+		score := max(b.scorer(api.GetNodeLoad(node)), 0)
+		weights[i] = 1 / float64(score+1)
+		total += weights[i]
+	}
+	r := rand.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return nodeArray[i]
+		}
+		r -= weight
+	}
+	return nodeArray[len(nodeArray)-1]
+}
@@ -12,11 +12,18 @@ import (
 	"github.com/dingqinghui/gas/api"
 )
 
-func NewPid(service string, lb api.IBalancer, user interface{}) *api.Pid {
-	if api.GetNode() == nil || api.GetNode().Discovery() == nil {
+// NewPid
+// @Description: 通过负载均衡在提供service的节点中选出一个,返回按名字寻址的pid
+// @param node 当前节点
+// @param service
+// @param lb
+// @param user
+// @return *api.Pid
+func NewPid(node api.INode, service string, lb api.IBalancer, user interface{}) *api.Pid {
+	if node == nil || node.Discovery() == nil {
 		return nil
 	}
-	nodes := api.GetNode().Discovery().GetByKind(service)
+	nodes := node.Discovery().GetByKind(service)
 	selectNode := lb.Do(nodes, user)
 	if selectNode == nil {
 		return nil
@@ -14,14 +14,16 @@ import (
 	"github.com/dingqinghui/gas/zlog"
 	"github.com/duke-git/lancet/v2/convertor"
 	"golang.org/x/exp/slices"
+	"sync"
 )
 
-func New(clusterName string, provider api.IDiscoveryProvider) api.IDiscovery {
-	if api.GetNode() == nil {
+func New(node api.INode, clusterName string, provider api.IDiscoveryProvider) api.IDiscovery {
+	if node == nil {
 		return nil
 	}
 	d := new(discovery)
-	api.GetNode().AddModule(d)
+	d.node = node
+	node.AddModule(d)
 	xerror.NilAssert(provider)
 	d.provider = provider
 	d.clusterName = clusterName
@@ -31,9 +33,13 @@ func New(clusterName string, provider api.IDiscoveryProvider) api.IDiscovery {
 
 type discovery struct {
 	api.BuiltinModule
+	node        api.INode
 	provider    api.IDiscoveryProvider
 	clusterName string
 	list        *NodeList
+	listLock    sync.RWMutex
+	handlerLock sync.Mutex
+	handlers    []api.TopologyHandler
 }
 
 func (d *discovery) Init() {
@@ -45,29 +51,62 @@ func (d *discovery) Name() string {
 }
 
 func (d *discovery) Run() {
-	if d.provider == nil || api.GetNode() == nil {
+	if d.provider == nil || d.node == nil {
 		return
 	}
 	// watch node
 	api.Assert(d.provider.WatchNode(d.clusterName, func(waitIndex uint64, nodeDict map[uint64]*api.BaseNode) {
+		d.listLock.Lock()
 		if waitIndex <= d.list.LastEventId {
+			d.listLock.Unlock()
 			return
 		}
 		topology := d.list.UpdateClusterTopology(nodeDict, waitIndex)
+		d.listLock.Unlock()
+		if len(topology.Left) != 0 {
+			d.node.System().HandleTopology(topology)
+		}
 		if len(topology.Left) != 0 || len(topology.Joined) != 0 {
-			_ = api.GetNode().System().Group().Broadcast(api.ClusterUpdateGroup, nil, topology)
+			d.notifyTopology(topology)
+			_ = d.node.System().Group().Broadcast(api.ClusterUpdateGroup, nil, topology)
 		}
 	}))
 	// add node
-	api.Assert(d.AddNode(api.GetNode().Base()))
+	api.Assert(d.AddNode(d.node.Base()))
+}
+
+// AddTopologyHandler
+// @Description: 注册集群拓扑变化回调
+// @receiver d
+// @param handler
+func (d *discovery) AddTopologyHandler(handler api.TopologyHandler) {
+	if handler == nil {
+		return
+	}
+	d.handlerLock.Lock()
+	defer d.handlerLock.Unlock()
+	d.handlers = append(d.handlers, handler)
+}
+
+func (d *discovery) notifyTopology(topology *api.Topology) {
+	d.handlerLock.Lock()
+	handlers := d.handlers
+	d.handlerLock.Unlock()
+	for _, handler := range handlers {
+		d.node.Try(func() { handler(topology) }, nil)
+	}
 }
 
 func (d *discovery) GetById(nodeId uint64) api.INodeBase {
+	d.listLock.RLock()
+	defer d.listLock.RUnlock()
 	v, _ := d.list.Dict[nodeId]
 	return v
 }
 
 func (d *discovery) GetByKind(kind string) (result []api.INodeBase) {
+	d.listLock.RLock()
+	defer d.listLock.RUnlock()
 	for _, node := range d.list.Dict {
 		if slices.Contains(node.GetTags(), kind) {
 			result = append(result, convertor.DeepClone(node))
@@ -77,6 +116,8 @@ func (d *discovery) GetByKind(kind string) (result []api.INodeBase) {
 }
 
 func (d *discovery) GetAll() (result []api.INodeBase) {
+	d.listLock.RLock()
+	defer d.listLock.RUnlock()
 	for _, node := range d.list.Dict {
 		result = append(result, convertor.DeepClone(node))
 	}
@@ -90,6 +131,18 @@ func (d *discovery) AddNode(node api.INodeBase) *api.Error {
 	return d.provider.AddNode(node)
 }
 
+// UpdateNode
+// @Description: 更新已注册节点的信息,用于发布负载等动态meta
+// @receiver d
+// @param node
+// @return *api.Error
+func (d *discovery) UpdateNode(node api.INodeBase) *api.Error {
+	if d.provider == nil {
+		return api.ErrDiscoveryProviderIsNil
+	}
+	return d.provider.UpdateNode(node)
+}
+
 func (d *discovery) RemoveNode(nodeId string) *api.Error {
 	if d.provider == nil {
 		return api.ErrDiscoveryProviderIsNil
@@ -98,13 +151,13 @@ func (d *discovery) RemoveNode(nodeId string) *api.Error {
 }
 
 func (d *discovery) Stop() *api.Error {
-	if api.GetNode() == nil {
+	if d.node == nil {
 		return nil
 	}
 	if err := d.BuiltinStopper.Stop(); err != nil {
 		return err
 	}
-	if err := d.RemoveNode(convertor.ToString(api.GetNode().GetID())); err != nil {
+	if err := d.RemoveNode(convertor.ToString(d.node.GetID())); err != nil {
 		return err
 	}
 	if d.provider != nil {
@@ -9,17 +9,16 @@
 package consul
 
 import (
-	"github.com/dingqinghui/gas/api"
+	"github.com/spf13/viper"
 	"time"
 )
 
-func initConfig() *config {
-	node := api.GetNode()
-	if node == nil {
+func initConfig(nodeVp *viper.Viper) *config {
+	if nodeVp == nil {
 		return nil
 	}
 	c := new(config)
-	vp := node.GetViper().Sub("cluster.consul")
+	vp := nodeVp.Sub("cluster.consul")
 	c.address = vp.GetString("address")
 	c.watchWaitTime = vp.GetDuration("watchWaitTime")
 	c.healthTtl = vp.GetDuration("healthTtl")
@@ -13,6 +13,7 @@ import (
 	"github.com/dingqinghui/gas/zlog"
 	"github.com/duke-git/lancet/v2/convertor"
 	"github.com/hashicorp/consul/api"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"time"
 )
@@ -24,16 +25,20 @@ type consulProvider struct {
 	waitIndex uint64
 	status    string
 	cfg       *config
+	vp        *viper.Viper
 }
 
-func NewConsulProvider() (api2.IDiscoveryProvider, error) {
-	c := new(consulProvider)
+// NewConsulProvider
+// @Description: consul发现,读取cluster.consul配置
+// @param vp 节点配置
+func NewConsulProvider(vp *viper.Viper) (api2.IDiscoveryProvider, error) {
+	c := &consulProvider{vp: vp}
 	c.Init()
 	return c, nil
 }
 
 func (c *consulProvider) Init() {
-	c.cfg = initConfig()
+	c.cfg = initConfig(c.vp)
 	c.status = "pass"
 	if c.cfg == nil {
 		return
@@ -117,6 +122,36 @@ func (c *consulProvider) AddNode(node api2.INodeBase) *api2.Error {
 		TTL:                            (c.cfg.healthTtl).String(),
 		DeregisterCriticalServiceAfter: (c.cfg.deregister).String(),
 	}
+	if err := c.register(node, check); err != nil {
+		return err
+	}
+
+	go c.healthCheckActor(node.GetID())
+
+	zlog.Info("consul node  register ", zap.Uint64("nodeId", node.GetID()),
+		zap.String("nodeName", node.GetName()), zap.String("address", node.GetAddress()),
+		zap.Int("port", node.GetPort()), zap.Strings("tags", node.GetTags()))
+	return nil
+}
+
+// UpdateNode
+// @Description: 重新注册更新meta,检查状态保持通过,不重复启动健康检查
+// @receiver c
+// @param node
+// @return *api2.Error
+func (c *consulProvider) UpdateNode(node api2.INodeBase) *api2.Error {
+	if c.cfg == nil {
+		return nil
+	}
+	check := &api.AgentServiceCheck{
+		TTL:                            (c.cfg.healthTtl).String(),
+		DeregisterCriticalServiceAfter: (c.cfg.deregister).String(),
+		Status:                         api.HealthPassing,
+	}
+	return c.register(node, check)
+}
+
+func (c *consulProvider) register(node api2.INodeBase, check *api.AgentServiceCheck) *api2.Error {
 	registration := &api.AgentServiceRegistration{
 		ID:      convertor.ToString(node.GetID()),
 		Name:    node.GetName(),
@@ -130,26 +165,17 @@ func (c *consulProvider) AddNode(node api2.INodeBase) *api2.Error {
 		zlog.Error("consul node  register err", zap.Uint64("nodeId", node.GetID()), zap.Error(err))
 		return api2.ErrConsul
 	}
-
-	go c.healthCheckActor()
-
-	zlog.Info("consul node  register ", zap.Uint64("nodeId", node.GetID()),
-		zap.String("nodeName", node.GetName()), zap.String("address", node.GetAddress()),
-		zap.Int("port", node.GetPort()), zap.Strings("tags", node.GetTags()))
 	return nil
 }
 
-func (c *consulProvider) healthCheckActor() {
-	if api2.GetNode() == nil {
-		return
-	}
+func (c *consulProvider) healthCheckActor(nodeId uint64) {
 	if c.cfg == nil {
 		return
 	}
 	zlog.Info("consul health check begin")
 	for !c.IsStop() {
-		if err := c.client.Agent().UpdateTTL("service:"+convertor.ToString(api2.GetNode().GetID()), "", c.status); err != nil {
-			zlog.Error("consul health agent err", zap.Uint64("nodeId", api2.GetNode().GetID()),
+		if err := c.client.Agent().UpdateTTL("service:"+convertor.ToString(nodeId), "", c.status); err != nil {
+			zlog.Error("consul health agent err", zap.Uint64("nodeId", nodeId),
 				zap.String("status", c.status), zap.Error(err))
 			return
 		}
@@ -0,0 +1,86 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: config
+ * @Version: 1.0.0
+ * @Date: 2025/1/28 10:10
+ */
+
+package gossip
+
+import (
+	"github.com/spf13/viper"
+	"time"
+)
+
+func initConfig(vp *viper.Viper) *config {
+	c := &config{
+		bind:             "127.0.0.1:7946",
+		probeInterval:    time.Second,
+		probeTimeout:     500 * time.Millisecond,
+		indirectChecks:   3,
+		suspicionTimeout: 5 * time.Second,
+		gossipInterval:   200 * time.Millisecond,
+		gossipFanout:     3,
+		pushPullInterval: 15 * time.Second,
+		deadReclaimTime:  30 * time.Second,
+		retransmitMult:   4,
+	}
+	if vp == nil {
+		return c
+	}
+	sub := vp.Sub("cluster.gossip")
+	if sub == nil {
+		return c
+	}
+	if sub.IsSet("bind") {
+		c.bind = sub.GetString("bind")
+	}
+	c.advertise = sub.GetString("advertise")
+	c.seeds = sub.GetStringSlice("seeds")
+	setDuration(sub, "probeInterval", &c.probeInterval)
+	setDuration(sub, "probeTimeout", &c.probeTimeout)
+	setDuration(sub, "suspicionTimeout", &c.suspicionTimeout)
+	setDuration(sub, "gossipInterval", &c.gossipInterval)
+	setDuration(sub, "pushPullInterval", &c.pushPullInterval)
+	setDuration(sub, "deadReclaimTime", &c.deadReclaimTime)
+	if sub.IsSet("indirectChecks") {
+		c.indirectChecks = sub.GetInt("indirectChecks")
+	}
+	if sub.IsSet("gossipFanout") {
+		c.gossipFanout = sub.GetInt("gossipFanout")
+	}
+	return c
+}
+
+func setDuration(vp *viper.Viper, key string, v *time.Duration) {
+	if vp.IsSet(key) {
+		*v = vp.GetDuration(key)
+	}
+}
+
+type config struct {
+	// bind 本节点gossip监听的udp地址
+	bind string
+	// advertise 其他节点访问本节点的地址,为空时使用bind的地址,bind为0.0.0.0时使用节点地址加bind的端口
+	advertise string
+	// seeds 启动时加入集群的种子节点地址
+	seeds []string
+	// probeInterval 每轮探测一个节点的间隔
+	probeInterval time.Duration
+	// probeTimeout 直接探测等待ack的时间,超时后请求其他节点间接探测
+	probeTimeout time.Duration
+	// indirectChecks 间接探测的节点数量
+	indirectChecks int
+	// suspicionTimeout 怀疑状态持续多久没有反驳判定死亡
+	suspicionTimeout time.Duration
+	// gossipInterval 主动传播状态变化的间隔
+	gossipInterval time.Duration
+	gossipFanout   int
+	// pushPullInterval 与随机节点全量同步的间隔,修复丢失的消息
+	pushPullInterval time.Duration
+	// deadReclaimTime 死亡节点保留多久,防止过期消息把节点复活
+	deadReclaimTime time.Duration
+	// retransmitMult 每条状态变化传播 retransmitMult*log(n+1) 次
+	retransmitMult int
+}
@@ -0,0 +1,114 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: member
+ * @Version: 1.0.0
+ * @Date: 2025/1/28 10:30
+ */
+
+package gossip
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"math"
+	"time"
+)
+
+type memberState int
+
+const (
+	stateAlive memberState = iota
+	stateSuspect
+	stateDead
+)
+
+func (s memberState) String() string {
+	switch s {
+	case stateAlive:
+		return "alive"
+	case stateSuspect:
+		return "suspect"
+	case stateDead:
+		return "dead"
+	}
+	return "unknown"
+}
+
+// member
+// @Description: 节点状态,同时也是gossip传播的消息
+type member struct {
+	Node        *api.BaseNode
+	Addr        string
+	Incarnation uint64
+	State       memberState
+}
+
+func (m *member) clone() *member {
+	c := *m
+	c.Node = copyNode(m.Node)
+	return &c
+}
+
+type memberInfo struct {
+	*member
+	stateTime time.Time
+	suspect   *time.Timer
+}
+
+type (
+	packetType int
+
+	// packet
+	// @Description: udp包,所有包都捎带状态变化
+	packet struct {
+		// Cluster 集群名,不同集群共用种子节点时互不合并
+		Cluster string
+		Type    packetType
+		Seq     uint64
+		// From 发送方地址,取udp包的源地址,不参与序列化
+		From     string `json:"-"`
+		Target   string
+		TargetId uint64
+		Members  []*member
+		Updates  []*member
+	}
+
+	broadcast struct {
+		member   *member
+		transmit int
+	}
+)
+
+const (
+	packetPing packetType = iota
+	packetAck
+	packetPingReq
+	packetSync
+	packetSyncReply
+	// packetGossip 只用于捎带状态变化
+	packetGossip
+)
+
+// retransmitLimit
+// @Description: 每条状态变化的传播次数随集群规模对数增长
+func retransmitLimit(mult, n int) int {
+	return mult * int(math.Ceil(math.Log10(float64(n+1))))
+}
+
+func copyNode(node api.INodeBase) *api.BaseNode {
+	if node == nil {
+		return nil
+	}
+	meta := make(map[string]string, len(node.GetMeta()))
+	for k, v := range node.GetMeta() {
+		meta[k] = v
+	}
+	return &api.BaseNode{
+		Id:      node.GetID(),
+		Name:    node.GetName(),
+		Address: node.GetAddress(),
+		Port:    node.GetPort(),
+		Tags:    append([]string(nil), node.GetTags()...),
+		Meta:    meta,
+	}
+}
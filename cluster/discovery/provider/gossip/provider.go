@@ -0,0 +1,640 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: provider
+ * @Version: 1.0.0
+ * @Date: 2025/1/28 11:00
+ */
+
+package gossip
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/serializer"
+	"github.com/dingqinghui/gas/zlog"
+	"github.com/duke-git/lancet/v2/convertor"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	maxPacketSize = 65507
+	maxPiggyback  = 8
+)
+
+// NewGossipProvider
+// @Description: SWIM协议的节点发现,节点之间通过udp探测和传播状态,不依赖外部服务
+// @param vp 读取cluster.gossip配置
+func NewGossipProvider(vp *viper.Viper) api.IDiscoveryProvider {
+	p := &gossipProvider{cfg: initConfig(vp)}
+	p.Init()
+	return p
+}
+
+type gossipProvider struct {
+	api.BuiltinModule
+	sync.Mutex
+	cfg         *config
+	clusterName string
+	conn        net.PacketConn
+	self        *member
+	leaving     bool
+	members     map[uint64]*memberInfo
+	broadcasts  []*broadcast
+	seq         uint64
+	acks        map[uint64]func()
+	probeIndex  int
+	index       uint64
+	f           api.EventNodeUpdateHandler
+	notify      chan struct{}
+	stopChan    chan struct{}
+}
+
+func (p *gossipProvider) Init() {
+	p.members = make(map[uint64]*memberInfo)
+	p.acks = make(map[uint64]func())
+	p.notify = make(chan struct{}, 1)
+	p.stopChan = make(chan struct{})
+}
+
+func (p *gossipProvider) Name() string {
+	return "gossip"
+}
+
+// WatchNode
+// @Description: 开始监听gossip端口,节点变化时回调f
+func (p *gossipProvider) WatchNode(clusterName string, f api.EventNodeUpdateHandler) *api.Error {
+	conn, err := net.ListenPacket("udp", p.cfg.bind)
+	if err != nil {
+		zlog.Error("gossip listen", zap.String("bind", p.cfg.bind), zap.Error(err))
+		return api.ErrGossip
+	}
+	p.conn = conn
+	p.clusterName = clusterName
+	p.f = f
+	go p.readLoop()
+	go p.notifyLoop()
+	go p.ticker(p.cfg.probeInterval, p.probe)
+	go p.ticker(p.cfg.gossipInterval, p.gossip)
+	go p.ticker(p.cfg.pushPullInterval, p.pushPull)
+	zlog.Info("gossip listen", zap.String("clusterName", clusterName), zap.String("bind", conn.LocalAddr().String()))
+	return nil
+}
+
+// AddNode
+// @Description: 本节点加入集群,向种子节点同步全量状态
+func (p *gossipProvider) AddNode(node api.INodeBase) *api.Error {
+	if p.conn == nil {
+		return api.ErrGossip
+	}
+	p.Lock()
+	// 用启动时间作为初始版本,重启后的节点总是比旧的死亡记录新
+	p.self = &member{
+		Node:        copyNode(node),
+		Addr:        p.advertise(node),
+		Incarnation: uint64(time.Now().UnixNano()),
+		State:       stateAlive,
+	}
+	p.queueBroadcast(p.self)
+	p.Unlock()
+	p.wakeup()
+	go p.join()
+	zlog.Info("gossip node join", zap.Uint64("nodeId", node.GetID()), zap.String("addr", p.self.Addr),
+		zap.Strings("seeds", p.cfg.seeds))
+	return nil
+}
+
+// advertise
+// @Description: 其他节点访问本节点的地址
+// @receiver p
+// @param node
+// @return string
+func (p *gossipProvider) advertise(node api.INodeBase) string {
+	if p.cfg.advertise != "" {
+		return p.cfg.advertise
+	}
+	local, ok := p.conn.LocalAddr().(*net.UDPAddr)
+	if !ok || !local.IP.IsUnspecified() || node.GetAddress() == "" {
+		return p.conn.LocalAddr().String()
+	}
+	return net.JoinHostPort(node.GetAddress(), convertor.ToString(local.Port))
+}
+
+// join
+// @Description: 向种子节点同步全量状态,没有发现其他节点前按探测间隔重试,种子节点晚于本节点启动时也能加入
+// @receiver p
+func (p *gossipProvider) join() {
+	for {
+		sent := false
+		for _, seed := range p.cfg.seeds {
+			if seed == p.self.Addr {
+				continue
+			}
+			p.send(seed, &packet{Type: packetSync, Members: p.snapshot()})
+			sent = true
+		}
+		if !sent {
+			return
+		}
+		select {
+		case <-p.stopChan:
+			return
+		case <-time.After(p.cfg.probeInterval):
+		}
+		p.Lock()
+		joined := len(p.members) > 0
+		p.Unlock()
+		if joined {
+			return
+		}
+	}
+}
+
+// UpdateNode
+// @Description: 递增版本号传播新的tags和meta
+func (p *gossipProvider) UpdateNode(node api.INodeBase) *api.Error {
+	p.Lock()
+	defer p.Unlock()
+	if p.self == nil {
+		return api.ErrGossip
+	}
+	p.self.Node = copyNode(node)
+	p.self.Incarnation++
+	p.queueBroadcast(p.self)
+	p.wakeup()
+	return nil
+}
+
+// RemoveNode
+// @Description: 本节点主动离开,直接通知部分节点,其他节点通过gossip得知
+func (p *gossipProvider) RemoveNode(nodeId string) *api.Error {
+	p.Lock()
+	if p.self == nil || convertor.ToString(p.self.Node.GetID()) != nodeId {
+		p.Unlock()
+		return nil
+	}
+	p.leaving = true
+	p.self.State = stateDead
+	p.queueBroadcast(p.self)
+	targets := p.randomMembers(p.cfg.gossipFanout, 0)
+	p.Unlock()
+	for _, target := range targets {
+		p.send(target.Addr, &packet{Type: packetGossip})
+	}
+	zlog.Info("gossip node leave", zap.String("nodeId", nodeId))
+	return nil
+}
+
+func (p *gossipProvider) Stop() *api.Error {
+	if err := p.BuiltinStopper.Stop(); err != nil {
+		return err
+	}
+	close(p.stopChan)
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	return nil
+}
+
+func (p *gossipProvider) ticker(interval time.Duration, f func()) {
+	if interval <= 0 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-t.C:
+			f()
+		}
+	}
+}
+
+// probe
+// @Description: 探测一个节点,直接探测超时后请求其他节点间接探测,都失败标记为怀疑
+// @receiver p
+func (p *gossipProvider) probe() {
+	p.Lock()
+	p.reclaim()
+	target := p.nextProbeTarget()
+	if target == nil {
+		p.Unlock()
+		return
+	}
+	acked := make(chan struct{}, 1)
+	seq := p.nextSeq(func() {
+		select {
+		case acked <- struct{}{}:
+		default:
+		}
+	})
+	targetAddr, targetId, incarnation := target.Addr, target.Node.GetID(), target.Incarnation
+	p.Unlock()
+	defer p.removeAck(seq)
+
+	p.send(targetAddr, &packet{Type: packetPing, Seq: seq, TargetId: targetId})
+	select {
+	case <-acked:
+		return
+	case <-time.After(p.cfg.probeTimeout):
+	case <-p.stopChan:
+		return
+	}
+
+	p.Lock()
+	helpers := p.randomMembers(p.cfg.indirectChecks, targetId)
+	p.Unlock()
+	for _, helper := range helpers {
+		p.send(helper.Addr, &packet{Type: packetPingReq, Seq: seq, Target: targetAddr, TargetId: targetId})
+	}
+	select {
+	case <-acked:
+		return
+	case <-time.After(p.cfg.probeInterval - p.cfg.probeTimeout):
+	case <-p.stopChan:
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	zlog.Warn("gossip probe failed", zap.Uint64("nodeId", targetId), zap.String("addr", targetAddr))
+	p.applySuspect(&member{Node: &api.BaseNode{Id: targetId}, Incarnation: incarnation, State: stateSuspect})
+}
+
+// gossip
+// @Description: 把待传播的状态变化发给随机节点
+// @receiver p
+func (p *gossipProvider) gossip() {
+	p.Lock()
+	if len(p.broadcasts) == 0 {
+		p.Unlock()
+		return
+	}
+	targets := p.randomMembers(p.cfg.gossipFanout, 0)
+	p.Unlock()
+	for _, target := range targets {
+		p.send(target.Addr, &packet{Type: packetGossip})
+	}
+}
+
+// pushPull
+// @Description: 与随机节点交换全量状态
+// @receiver p
+func (p *gossipProvider) pushPull() {
+	p.Lock()
+	targets := p.randomMembers(1, 0)
+	p.Unlock()
+	for _, target := range targets {
+		p.send(target.Addr, &packet{Type: packetSync, Members: p.snapshot()})
+	}
+}
+
+func (p *gossipProvider) readLoop() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			if p.IsStop() {
+				return
+			}
+			zlog.Error("gossip read", zap.Error(err))
+			continue
+		}
+		pkt := new(packet)
+		if err := serializer.Json.Unmarshal(buf[:n], pkt); err != nil {
+			zlog.Warn("gossip unmarshal", zap.String("from", addr.String()), zap.Error(err))
+			continue
+		}
+		if pkt.Cluster != p.clusterName {
+			zlog.Debug("gossip other cluster", zap.String("from", addr.String()), zap.String("cluster", pkt.Cluster))
+			continue
+		}
+		// 回复发往udp源地址,监听0.0.0.0时也能回到发送方
+		pkt.From = addr.String()
+		p.handle(pkt)
+	}
+}
+
+func (p *gossipProvider) handle(pkt *packet) {
+	p.Lock()
+	p.merge(pkt.Updates)
+	var selfId uint64
+	if p.self != nil {
+		selfId = p.self.Node.GetID()
+	}
+	p.Unlock()
+
+	switch pkt.Type {
+	case packetPing:
+		// 地址被重启的其他节点复用时不回复
+		if pkt.TargetId != 0 && pkt.TargetId != selfId {
+			return
+		}
+		p.send(pkt.From, &packet{Type: packetAck, Seq: pkt.Seq})
+	case packetAck:
+		p.Lock()
+		ack, ok := p.acks[pkt.Seq]
+		p.Unlock()
+		if ok {
+			ack()
+		}
+	case packetPingReq:
+		p.indirectPing(pkt)
+	case packetSync:
+		p.Lock()
+		p.merge(pkt.Members)
+		p.Unlock()
+		p.send(pkt.From, &packet{Type: packetSyncReply, Members: p.snapshot()})
+	case packetSyncReply:
+		p.Lock()
+		p.merge(pkt.Members)
+		p.Unlock()
+	}
+}
+
+// indirectPing
+// @Description: 代替其他节点探测目标,收到ack后转发给请求方
+// @receiver p
+// @param pkt
+func (p *gossipProvider) indirectPing(pkt *packet) {
+	requester, requestSeq := pkt.From, pkt.Seq
+	p.Lock()
+	seq := p.nextSeq(func() {
+		p.send(requester, &packet{Type: packetAck, Seq: requestSeq})
+	})
+	p.Unlock()
+	p.send(pkt.Target, &packet{Type: packetPing, Seq: seq, TargetId: pkt.TargetId})
+	time.AfterFunc(p.cfg.probeTimeout, func() { p.removeAck(seq) })
+}
+
+// send
+// @Description: 发送时捎带待传播的状态变化
+// @receiver p
+// @param addr
+// @param pkt
+func (p *gossipProvider) send(addr string, pkt *packet) {
+	if p.IsStop() || addr == "" {
+		return
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		zlog.Warn("gossip resolve addr", zap.String("addr", addr), zap.Error(err))
+		return
+	}
+	pkt.Cluster = p.clusterName
+	p.Lock()
+	pkt.Updates = p.piggyback()
+	p.Unlock()
+	data, err := serializer.Json.Marshal(pkt)
+	if err != nil {
+		zlog.Error("gossip marshal", zap.Error(err))
+		return
+	}
+	if _, err = p.conn.WriteTo(data, udpAddr); err != nil && !p.IsStop() {
+		zlog.Warn("gossip send", zap.String("addr", addr), zap.Error(err))
+	}
+}
+
+func (p *gossipProvider) merge(members []*member) {
+	for _, m := range members {
+		if m == nil || m.Node == nil {
+			continue
+		}
+		switch m.State {
+		case stateAlive:
+			p.applyAlive(m)
+		case stateSuspect:
+			p.applySuspect(m)
+		case stateDead:
+			p.applyDead(m)
+		}
+	}
+}
+
+// applyAlive
+// @Description: 版本更高的存活消息覆盖怀疑和死亡状态,同时更新tags和meta
+func (p *gossipProvider) applyAlive(m *member) {
+	if p.isSelf(m) {
+		return
+	}
+	cur, ok := p.members[m.Node.GetID()]
+	if ok && m.Incarnation <= cur.Incarnation {
+		return
+	}
+	if ok && cur.suspect != nil {
+		cur.suspect.Stop()
+	}
+	p.members[m.Node.GetID()] = &memberInfo{member: m.clone(), stateTime: time.Now()}
+	p.queueBroadcast(m)
+	p.wakeup()
+}
+
+// applySuspect
+// @Description: 怀疑本节点时递增版本反驳,怀疑其他节点超时后判定死亡
+func (p *gossipProvider) applySuspect(m *member) {
+	if p.isSelf(m) {
+		p.refute(m.Incarnation)
+		return
+	}
+	cur, ok := p.members[m.Node.GetID()]
+	if !ok || cur.State == stateDead || m.Incarnation < cur.Incarnation {
+		return
+	}
+	if cur.State == stateSuspect && m.Incarnation == cur.Incarnation {
+		return
+	}
+	cur.Incarnation = m.Incarnation
+	cur.State = stateSuspect
+	cur.stateTime = time.Now()
+	nodeId, incarnation := cur.Node.GetID(), cur.Incarnation
+	cur.suspect = time.AfterFunc(p.cfg.suspicionTimeout, func() {
+		p.Lock()
+		defer p.Unlock()
+		if info, ok := p.members[nodeId]; ok && info.State == stateSuspect && info.Incarnation == incarnation {
+			zlog.Warn("gossip suspect timeout", zap.Uint64("nodeId", nodeId))
+			p.applyDead(&member{Node: info.Node, Incarnation: incarnation, State: stateDead})
+		}
+	})
+	p.queueBroadcast(cur.member)
+	p.wakeup()
+}
+
+// applyDead
+// @Description: 死亡或主动离开,保留一段时间后删除
+func (p *gossipProvider) applyDead(m *member) {
+	if p.isSelf(m) {
+		if !p.leaving {
+			p.refute(m.Incarnation)
+		}
+		return
+	}
+	cur, ok := p.members[m.Node.GetID()]
+	if !ok || cur.State == stateDead || m.Incarnation < cur.Incarnation {
+		return
+	}
+	if cur.suspect != nil {
+		cur.suspect.Stop()
+	}
+	cur.Incarnation = m.Incarnation
+	cur.State = stateDead
+	cur.stateTime = time.Now()
+	p.queueBroadcast(cur.member)
+	p.wakeup()
+}
+
+func (p *gossipProvider) refute(incarnation uint64) {
+	if p.self == nil || incarnation < p.self.Incarnation {
+		return
+	}
+	p.self.Incarnation = incarnation + 1
+	p.queueBroadcast(p.self)
+	zlog.Warn("gossip refute suspect", zap.Uint64("incarnation", p.self.Incarnation))
+}
+
+func (p *gossipProvider) isSelf(m *member) bool {
+	return p.self != nil && m.Node.GetID() == p.self.Node.GetID()
+}
+
+// reclaim
+// @Description: 删除死亡超过deadReclaimTime的节点
+func (p *gossipProvider) reclaim() {
+	for id, info := range p.members {
+		if info.State == stateDead && time.Since(info.stateTime) > p.cfg.deadReclaimTime {
+			delete(p.members, id)
+		}
+	}
+}
+
+// queueBroadcast
+// @Description: 同一节点只保留最新的状态变化
+func (p *gossipProvider) queueBroadcast(m *member) {
+	for i, b := range p.broadcasts {
+		if b.member.Node.GetID() == m.Node.GetID() {
+			p.broadcasts = append(p.broadcasts[:i], p.broadcasts[i+1:]...)
+			break
+		}
+	}
+	p.broadcasts = append(p.broadcasts, &broadcast{member: m.clone()})
+}
+
+// piggyback
+// @Description: 优先捎带传播次数少的状态变化,超过次数后丢弃
+func (p *gossipProvider) piggyback() []*member {
+	if len(p.broadcasts) == 0 {
+		return nil
+	}
+	limit := retransmitLimit(p.cfg.retransmitMult, len(p.members)+1)
+	sort.SliceStable(p.broadcasts, func(i, j int) bool {
+		return p.broadcasts[i].transmit < p.broadcasts[j].transmit
+	})
+	var result []*member
+	remain := p.broadcasts[:0]
+	for _, b := range p.broadcasts {
+		if len(result) < maxPiggyback {
+			result = append(result, b.member)
+			b.transmit++
+		}
+		if b.transmit < limit {
+			remain = append(remain, b)
+		}
+	}
+	p.broadcasts = remain
+	return result
+}
+
+func (p *gossipProvider) snapshot() []*member {
+	p.Lock()
+	defer p.Unlock()
+	result := make([]*member, 0, len(p.members)+1)
+	if p.self != nil {
+		result = append(result, p.self.clone())
+	}
+	for _, info := range p.members {
+		result = append(result, info.member.clone())
+	}
+	return result
+}
+
+func (p *gossipProvider) nextProbeTarget() *member {
+	candidates := p.liveMembers(0)
+	if len(candidates) == 0 {
+		return nil
+	}
+	p.probeIndex++
+	return candidates[p.probeIndex%len(candidates)]
+}
+
+func (p *gossipProvider) randomMembers(n int, exclude uint64) []*member {
+	candidates := p.liveMembers(exclude)
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// liveMembers
+// @Description: 存活和怀疑的其他节点,按id排序
+func (p *gossipProvider) liveMembers(exclude uint64) []*member {
+	result := make([]*member, 0, len(p.members))
+	for id, info := range p.members {
+		if info.State == stateDead || id == exclude {
+			continue
+		}
+		result = append(result, info.member)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Node.GetID() < result[j].Node.GetID() })
+	return result
+}
+
+func (p *gossipProvider) nextSeq(ack func()) uint64 {
+	p.seq++
+	p.acks[p.seq] = ack
+	return p.seq
+}
+
+func (p *gossipProvider) removeAck(seq uint64) {
+	p.Lock()
+	defer p.Unlock()
+	delete(p.acks, seq)
+}
+
+func (p *gossipProvider) wakeup() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// notifyLoop
+// @Description: 合并状态变化,把存活和怀疑的节点交给discovery
+func (p *gossipProvider) notifyLoop() {
+	for {
+		select {
+		case <-p.stopChan:
+			return
+		case <-p.notify:
+		}
+		p.Lock()
+		nodes := make(map[uint64]*api.BaseNode, len(p.members)+1)
+		if p.self != nil && p.self.State != stateDead {
+			nodes[p.self.Node.GetID()] = copyNode(p.self.Node)
+		}
+		for id, info := range p.members {
+			if info.State != stateDead {
+				nodes[id] = copyNode(info.Node)
+			}
+		}
+		p.index++
+		index := p.index
+		p.Unlock()
+		p.f(index, nodes)
+	}
+}
@@ -0,0 +1,124 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: provider_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/28 16:20
+ */
+
+package gossip
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/spf13/viper"
+)
+
+// view
+// @Description: 记录最新的节点表
+type view struct {
+	sync.Mutex
+	nodes map[uint64]*api.BaseNode
+}
+
+func (v *view) len() int {
+	v.Lock()
+	defer v.Unlock()
+	return len(v.nodes)
+}
+
+func (v *view) get(id uint64) *api.BaseNode {
+	v.Lock()
+	defer v.Unlock()
+	return v.nodes[id]
+}
+
+func start(t *testing.T, cluster string, id uint64, bind string, seeds ...string) (*gossipProvider, *view) {
+	vp := viper.New()
+	vp.Set("cluster.gossip.bind", bind)
+	vp.Set("cluster.gossip.seeds", seeds)
+	vp.Set("cluster.gossip.probeInterval", "100ms")
+	vp.Set("cluster.gossip.probeTimeout", "40ms")
+	vp.Set("cluster.gossip.suspicionTimeout", "300ms")
+	vp.Set("cluster.gossip.gossipInterval", "20ms")
+	vp.Set("cluster.gossip.pushPullInterval", "500ms")
+	p := NewGossipProvider(vp).(*gossipProvider)
+	v := &view{}
+	err := p.WatchNode(cluster, func(_ uint64, nodes map[uint64]*api.BaseNode) {
+		v.Lock()
+		v.nodes = nodes
+		v.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = p.Stop() })
+	_ = p.AddNode(&api.BaseNode{Id: id, Name: cluster, Address: "127.0.0.1", Meta: map[string]string{}})
+	return p, v
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not reached")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// freeAddr
+// @Description: 预留一个本地udp地址,稍后再启动种子节点
+func freeAddr(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := conn.LocalAddr().String()
+	_ = conn.Close()
+	return addr
+}
+
+func TestGossip(t *testing.T) {
+	seed := freeAddr(t)
+	// 种子节点晚于其他节点启动,其他节点重试同步后加入
+	_, v2 := start(t, "gossip", 2, "0.0.0.0:0", seed)
+	p1, v1 := start(t, "gossip", 1, seed, seed)
+	waitFor(t, func() bool { return v1.len() == 2 && v2.len() == 2 })
+	// 监听0.0.0.0时广播节点地址加实际端口
+	if v1.get(2) == nil {
+		t.Fatal("node 2 missing")
+	}
+	p1.Lock()
+	addr := p1.members[2].Addr
+	p1.Unlock()
+	if host, _, _ := net.SplitHostPort(addr); host != "127.0.0.1" {
+		t.Fatalf("advertise addr = %s", addr)
+	}
+
+	p3, v3 := start(t, "gossip", 3, "127.0.0.1:0", seed)
+	waitFor(t, func() bool { return v1.len() == 3 && v2.len() == 3 && v3.len() == 3 })
+	_ = p3.UpdateNode(&api.BaseNode{Id: 3, Name: "gossip", Meta: map[string]string{"load": "9"}})
+	waitFor(t, func() bool { return v1.get(3).GetMeta()["load"] == "9" })
+
+	// 宕机的节点被判定死亡
+	_ = p3.Stop()
+	waitFor(t, func() bool { return v1.len() == 2 && v2.len() == 2 })
+}
+
+func TestClusterName(t *testing.T) {
+	seed := freeAddr(t)
+	_, v1 := start(t, "gossip-a", 1, seed, seed)
+	_, v2 := start(t, "gossip-b", 2, "127.0.0.1:0", seed)
+	_, v3 := start(t, "gossip-a", 3, "127.0.0.1:0", seed)
+	waitFor(t, func() bool { return v1.len() == 2 && v3.len() == 2 })
+	// 共用种子节点的其他集群不合并
+	time.Sleep(300 * time.Millisecond)
+	if v1.get(2) != nil || v2.len() != 1 {
+		t.Fatalf("clusters merged: %d %d", v1.len(), v2.len())
+	}
+}
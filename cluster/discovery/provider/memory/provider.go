@@ -0,0 +1,222 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: provider
+ * @Version: 1.0.0
+ * @Date: 2025/1/27 10:10
+ */
+
+package memory
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"github.com/duke-git/lancet/v2/convertor"
+	"go.uber.org/zap"
+	"sync"
+)
+
+var (
+	clusterLock sync.Mutex
+	clusters    = make(map[string]*Cluster)
+)
+
+// GetCluster
+// @Description: 进程内按集群名共享的节点表,同一进程中的多个节点使用相同集群名即可互相发现
+// @param name
+// @return *Cluster
+func GetCluster(name string) *Cluster {
+	clusterLock.Lock()
+	defer clusterLock.Unlock()
+	c, ok := clusters[name]
+	if !ok {
+		c = &Cluster{
+			nodes:    make(map[uint64]*api.BaseNode),
+			watchers: make(map[*memoryProvider]struct{}),
+		}
+		clusters[name] = c
+	}
+	return c
+}
+
+// Cluster
+// @Description: 进程内节点表,每次变化递增index并通知所有watch
+type Cluster struct {
+	sync.Mutex
+	index    uint64
+	nodes    map[uint64]*api.BaseNode
+	watchers map[*memoryProvider]struct{}
+}
+
+// Nodes
+// @Description: 当前节点快照
+// @receiver c
+// @return uint64 变化序号
+// @return map[uint64]*api.BaseNode
+func (c *Cluster) Nodes() (uint64, map[uint64]*api.BaseNode) {
+	c.Lock()
+	defer c.Unlock()
+	return c.index, c.snapshot()
+}
+
+// Put
+// @Description: 加入或更新节点
+// @receiver c
+// @param node
+func (c *Cluster) Put(node api.INodeBase) {
+	c.Lock()
+	c.nodes[node.GetID()] = copyNode(node)
+	c.index++
+	c.Unlock()
+	c.notify()
+}
+
+// Remove
+// @Description: 移除节点,测试中可用来模拟节点宕机
+// @receiver c
+// @param nodeId
+func (c *Cluster) Remove(nodeId uint64) {
+	c.Lock()
+	if _, ok := c.nodes[nodeId]; !ok {
+		c.Unlock()
+		return
+	}
+	delete(c.nodes, nodeId)
+	c.index++
+	c.Unlock()
+	c.notify()
+}
+
+func (c *Cluster) snapshot() map[uint64]*api.BaseNode {
+	nodes := make(map[uint64]*api.BaseNode, len(c.nodes))
+	for id, node := range c.nodes {
+		nodes[id] = copyNode(node)
+	}
+	return nodes
+}
+
+func (c *Cluster) notify() {
+	c.Lock()
+	defer c.Unlock()
+	for watcher := range c.watchers {
+		watcher.wakeup()
+	}
+}
+
+func (c *Cluster) watch(p *memoryProvider) {
+	c.Lock()
+	defer c.Unlock()
+	c.watchers[p] = struct{}{}
+}
+
+func (c *Cluster) unwatch(p *memoryProvider) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.watchers, p)
+}
+
+func copyNode(node api.INodeBase) *api.BaseNode {
+	meta := make(map[string]string, len(node.GetMeta()))
+	for k, v := range node.GetMeta() {
+		meta[k] = v
+	}
+	return &api.BaseNode{
+		Id:      node.GetID(),
+		Name:    node.GetName(),
+		Address: node.GetAddress(),
+		Port:    node.GetPort(),
+		Tags:    append([]string(nil), node.GetTags()...),
+		Meta:    meta,
+	}
+}
+
+// NewMemoryProvider
+// @Description: 进程内发现,用于单元测试和单进程多节点
+func NewMemoryProvider() api.IDiscoveryProvider {
+	p := new(memoryProvider)
+	p.Init()
+	return p
+}
+
+type memoryProvider struct {
+	api.BuiltinModule
+	cluster *Cluster
+	notify  chan struct{}
+}
+
+func (p *memoryProvider) Init() {
+	p.notify = make(chan struct{}, 1)
+}
+
+func (p *memoryProvider) Name() string {
+	return "memory"
+}
+
+func (p *memoryProvider) WatchNode(clusterName string, f api.EventNodeUpdateHandler) *api.Error {
+	p.cluster = GetCluster(clusterName)
+	p.cluster.watch(p)
+	index, nodes := p.cluster.Nodes()
+	f(index, nodes)
+	go func() {
+		for range p.notify {
+			if p.IsStop() {
+				return
+			}
+			index, nodes := p.cluster.Nodes()
+			f(index, nodes)
+		}
+	}()
+	return nil
+}
+
+// wakeup
+// @Description: 合并通知,watch协程每次读取最新快照
+func (p *memoryProvider) wakeup() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// AddNode
+// @Description: 加入WatchNode指定的集群,需要先WatchNode
+func (p *memoryProvider) AddNode(node api.INodeBase) *api.Error {
+	if p.cluster == nil {
+		return api.ErrDiscoveryProviderIsNil
+	}
+	p.cluster.Put(node)
+	zlog.Info("memory node register", zap.Uint64("nodeId", node.GetID()), zap.String("nodeName", node.GetName()))
+	return nil
+}
+
+func (p *memoryProvider) UpdateNode(node api.INodeBase) *api.Error {
+	if p.cluster == nil {
+		return api.ErrDiscoveryProviderIsNil
+	}
+	p.cluster.Put(node)
+	return nil
+}
+
+func (p *memoryProvider) RemoveNode(nodeId string) *api.Error {
+	if p.cluster == nil {
+		return nil
+	}
+	id, err := convertor.ToInt(nodeId)
+	if err != nil {
+		return api.ErrInvalidPid
+	}
+	p.cluster.Remove(uint64(id))
+	zlog.Info("memory node deregister", zap.String("nodeId", nodeId))
+	return nil
+}
+
+func (p *memoryProvider) Stop() *api.Error {
+	if err := p.BuiltinStopper.Stop(); err != nil {
+		return err
+	}
+	if p.cluster != nil {
+		p.cluster.unwatch(p)
+	}
+	close(p.notify)
+	return nil
+}
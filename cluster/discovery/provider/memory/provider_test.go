@@ -0,0 +1,73 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: provider_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/27 15:10
+ */
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+func watch(t *testing.T, p api.IDiscoveryProvider, clusterName string) chan map[uint64]*api.BaseNode {
+	ch := make(chan map[uint64]*api.BaseNode, 16)
+	if err := p.WatchNode(clusterName, func(_ uint64, nodes map[uint64]*api.BaseNode) { ch <- nodes }); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = p.Stop() })
+	return ch
+}
+
+// last
+// @Description: 等待通知合并后的最新快照
+func last(t *testing.T, ch chan map[uint64]*api.BaseNode) map[uint64]*api.BaseNode {
+	var nodes map[uint64]*api.BaseNode
+	select {
+	case nodes = <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+	for {
+		select {
+		case nodes = <-ch:
+		case <-time.After(50 * time.Millisecond):
+			return nodes
+		}
+	}
+}
+
+func TestProvider(t *testing.T) {
+	p1, p2 := NewMemoryProvider(), NewMemoryProvider()
+	if err := p1.AddNode(&api.BaseNode{Id: 1}); err != api.ErrDiscoveryProviderIsNil {
+		t.Fatalf("add before watch = %v", err)
+	}
+	ch1 := watch(t, p1, "memory-provider")
+	ch2 := watch(t, p2, "memory-provider")
+	// 节点名与集群名不同,按WatchNode的集群加入
+	_ = p1.AddNode(&api.BaseNode{Id: 1, Name: "gate"})
+	_ = p2.AddNode(&api.BaseNode{Id: 2, Name: "chat"})
+	if nodes := last(t, ch1); len(nodes) != 2 {
+		t.Fatalf("nodes = %v", nodes)
+	}
+	_ = p2.UpdateNode(&api.BaseNode{Id: 2, Meta: map[string]string{"load": "3"}})
+	if nodes := last(t, ch1); nodes[2].GetMeta()["load"] != "3" {
+		t.Fatalf("update = %v", nodes[2])
+	}
+	_ = p2.RemoveNode("2")
+	if nodes := last(t, ch1); len(nodes) != 1 || nodes[1] == nil {
+		t.Fatalf("remove = %v", nodes)
+	}
+	last(t, ch2)
+
+	// 其他集群互不可见
+	ch3 := watch(t, NewMemoryProvider(), "memory-other")
+	if nodes := last(t, ch3); len(nodes) != 0 {
+		t.Fatalf("other cluster = %v", nodes)
+	}
+}
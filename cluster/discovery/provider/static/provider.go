@@ -0,0 +1,150 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: provider
+ * @Version: 1.0.0
+ * @Date: 2025/1/27 11:00
+ */
+
+package static
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"sync"
+)
+
+const nodesKey = "cluster.static.nodes"
+
+// NewStaticProvider
+// @Description: 从配置文件cluster.static.nodes读取节点列表,监听文件变化,适合不依赖consul的开发集群
+// @param vp 节点的配置
+func NewStaticProvider(vp *viper.Viper) api.IDiscoveryProvider {
+	p := &staticProvider{vp: vp, meta: make(map[uint64]map[string]string)}
+	p.Init()
+	return p
+}
+
+type staticProvider struct {
+	api.BuiltinModule
+	sync.Mutex
+	vp    *viper.Viper
+	index uint64
+	f     api.EventNodeUpdateHandler
+	// nodes 最近一次读取的配置节点
+	nodes []*api.BaseNode
+	// meta UpdateNode发布的meta,合并到配置中的节点上
+	meta map[uint64]map[string]string
+}
+
+func (p *staticProvider) Name() string {
+	return "static"
+}
+
+func (p *staticProvider) WatchNode(clusterName string, f api.EventNodeUpdateHandler) *api.Error {
+	p.Lock()
+	p.f = f
+	p.Unlock()
+	p.reload(clusterName)
+	p.vp.OnConfigChange(func(e fsnotify.Event) {
+		if p.IsStop() {
+			return
+		}
+		zlog.Info("static discovery config change", zap.String("file", e.Name))
+		p.reload(clusterName)
+	})
+	p.vp.WatchConfig()
+	return nil
+}
+
+// reload
+// @Description: 每次读取配置都作为新的一轮,由NodeList计算加入和离开的节点
+// @receiver p
+// @param clusterName
+func (p *staticProvider) reload(clusterName string) {
+	var nodes []*api.BaseNode
+	if err := p.vp.UnmarshalKey(nodesKey, &nodes); err != nil {
+		zlog.Error("static discovery parse nodes", zap.Error(err))
+		return
+	}
+	valid := make([]*api.BaseNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil || node.Id == 0 {
+			continue
+		}
+		node.Name = clusterName
+		valid = append(valid, node)
+	}
+	p.Lock()
+	p.nodes = valid
+	index, nodeDict := p.snapshot()
+	f := p.f
+	p.Unlock()
+	// 回调可能重新进入provider,不能持有锁
+	f(index, nodeDict)
+}
+
+// snapshot
+// @Description: 配置中的节点合并meta后生成新的节点表,调用者持有锁
+// @receiver p
+// @return uint64
+// @return map[uint64]*api.BaseNode
+func (p *staticProvider) snapshot() (uint64, map[uint64]*api.BaseNode) {
+	nodeDict := make(map[uint64]*api.BaseNode, len(p.nodes))
+	for _, node := range p.nodes {
+		meta := make(map[string]string, len(node.Meta)+len(p.meta[node.Id]))
+		for k, v := range node.Meta {
+			meta[k] = v
+		}
+		for k, v := range p.meta[node.Id] {
+			meta[k] = v
+		}
+		n := *node
+		n.Meta = meta
+		nodeDict[node.Id] = &n
+	}
+	p.index++
+	return p.index, nodeDict
+}
+
+// AddNode
+// @Description: 节点列表以配置为准,本节点不在列表中时其他节点无法发现
+func (p *staticProvider) AddNode(node api.INodeBase) *api.Error {
+	var nodes []*api.BaseNode
+	_ = p.vp.UnmarshalKey(nodesKey, &nodes)
+	for _, n := range nodes {
+		if n != nil && n.Id == node.GetID() {
+			return nil
+		}
+	}
+	zlog.Warn("static discovery node not in config", zap.Uint64("nodeId", node.GetID()))
+	return nil
+}
+
+// UpdateNode
+// @Description: 节点列表和地址以配置为准,只合并meta(负载等)到内存中的节点表,不重新读取配置.
+// 各进程独立读取配置,其他进程看不到本节点发布的meta
+func (p *staticProvider) UpdateNode(node api.INodeBase) *api.Error {
+	p.Lock()
+	if p.f == nil {
+		p.Unlock()
+		return api.ErrDiscoveryProviderIsNil
+	}
+	meta := make(map[string]string, len(node.GetMeta()))
+	for k, v := range node.GetMeta() {
+		meta[k] = v
+	}
+	p.meta[node.GetID()] = meta
+	index, nodeDict := p.snapshot()
+	f := p.f
+	p.Unlock()
+	f(index, nodeDict)
+	return nil
+}
+
+func (p *staticProvider) RemoveNode(_ string) *api.Error {
+	return nil
+}
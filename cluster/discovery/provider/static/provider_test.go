@@ -0,0 +1,118 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: provider_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/27 15:40
+ */
+
+package static
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/spf13/viper"
+)
+
+func next(t *testing.T, ch chan map[uint64]*api.BaseNode) map[uint64]*api.BaseNode {
+	select {
+	case nodes := <-ch:
+		return nodes
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update")
+	}
+	return nil
+}
+
+func TestProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cluster.json")
+	config := `{"cluster":{"static":{"nodes":[{"id":1,"tags":["chat"],"meta":{"zone":"a"}}]}}}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vp := viper.New()
+	vp.SetConfigFile(path)
+	if err := vp.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	p := NewStaticProvider(vp)
+	if err := p.UpdateNode(&api.BaseNode{Id: 1}); err != api.ErrDiscoveryProviderIsNil {
+		t.Fatalf("update before watch = %v", err)
+	}
+	ch := make(chan map[uint64]*api.BaseNode, 16)
+	_ = p.WatchNode("static", func(_ uint64, nodes map[uint64]*api.BaseNode) { ch <- nodes })
+	t.Cleanup(func() { _ = p.Stop() })
+	nodes := next(t, ch)
+	if node := nodes[1]; node == nil || node.Tags[0] != "chat" || node.Meta["zone"] != "a" || node.Name != "static" {
+		t.Fatalf("nodes = %v", nodes)
+	}
+
+	// 发布的meta合并到配置中的节点上
+	_ = p.UpdateNode(&api.BaseNode{Id: 1, Meta: map[string]string{"load": "5"}})
+	nodes = next(t, ch)
+	if node := nodes[1]; node.Meta["load"] != "5" || node.Meta["zone"] != "a" {
+		t.Fatalf("update = %v", node)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	config = `{"cluster":{"static":{"nodes":[{"id":1},{"id":2,"port":9}]}}}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 一次写入可能触发多次变化通知,等待读到新配置
+	for nodes = next(t, ch); len(nodes) != 2; nodes = next(t, ch) {
+	}
+	if nodes[2].Port != 9 || nodes[1].Meta["load"] != "5" {
+		t.Fatalf("reload = %v", nodes)
+	}
+}
+
+func TestProviderReentrant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cluster.json")
+	config := `{"cluster":{"static":{"nodes":[{"id":1}]}}}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vp := viper.New()
+	vp.SetConfigFile(path)
+	if err := vp.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	p := NewStaticProvider(vp)
+	ch := make(chan map[uint64]*api.BaseNode, 16)
+	// 回调中发布meta,持有锁回调时会死锁
+	first := true
+	done := make(chan struct{})
+	go func() {
+		_ = p.WatchNode("static", func(_ uint64, nodes map[uint64]*api.BaseNode) {
+			if first {
+				first = false
+				_ = p.UpdateNode(&api.BaseNode{Id: 1, Meta: map[string]string{"load": "1"}})
+			}
+			ch <- nodes
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watch deadlock")
+	}
+	t.Cleanup(func() { _ = p.Stop() })
+	if nodes := next(t, ch); nodes[1].Meta["load"] != "1" {
+		t.Fatalf("update in callback = %v", nodes)
+	}
+	next(t, ch)
+
+	// UpdateNode只更新内存中的节点表,不重新读取配置
+	vp.Set(nodesKey, []map[string]interface{}{{"id": 2}})
+	_ = p.UpdateNode(&api.BaseNode{Id: 1, Meta: map[string]string{"load": "2"}})
+	nodes := next(t, ch)
+	if len(nodes) != 1 || nodes[1].Meta["load"] != "2" {
+		t.Fatalf("update = %v", nodes)
+	}
+}
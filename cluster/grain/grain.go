@@ -0,0 +1,277 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: grain
+ * @Version: 1.0.0
+ * @Date: 2025/1/21 10:10
+ */
+
+package grain
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// namePrefix 虚拟actor的注册名 grain/<kind>/<identity>
+const namePrefix = "grain/"
+
+type (
+	// Kind
+	// @Description: 虚拟actor类型,承载该类型的节点需在tags中配置kind
+	Kind struct {
+		Name     string
+		Producer api.ActorProducer
+		// IdleTimeout 空闲多久后停止,0不停止
+		IdleTimeout time.Duration
+		Options     []api.ProcessOption
+	}
+
+	// Identity
+	// @Description: 虚拟actor的InitParams
+	Identity struct {
+		Kind     string
+		Identity string
+	}
+)
+
+func NewKind(name string, producer api.ActorProducer, idleTimeout time.Duration, opts ...api.ProcessOption) *Kind {
+	return &Kind{
+		Name:        name,
+		Producer:    producer,
+		IdleTimeout: idleTimeout,
+		Options:     opts,
+	}
+}
+
+// Start
+// @Description: 注册本节点承载的虚拟actor类型,设置激活器,在节点Run之后调用
+// @param node
+// @param kinds
+// @return *Manager
+func Start(node api.INode, kinds ...*Kind) *Manager {
+	system := node.System()
+	m := &Manager{
+		node:        node,
+		system:      system,
+		kinds:       make(map[string]*Kind),
+		activations: make(map[string]*api.Pid),
+		pending:     make(map[string][]*api.Message),
+	}
+	for _, kind := range kinds {
+		m.kinds[kind.Name] = kind
+	}
+	system.SetActivator(m)
+	m.rebalancer = &Rebalancer{manager: m}
+	pid, err := system.Spawn(func() api.IActor { return m.rebalancer }, nil)
+	if err != nil {
+		zlog.Error("grain start rebalancer", zap.Error(err))
+	}
+	m.rebalancerPid = pid
+	return m
+}
+
+// Pid
+// @Description: 按(kind, identity)获取虚拟actor的地址,消息投递到拥有该identity的节点后按需激活
+// @param node 当前节点
+// @param kind
+// @param identity
+// @return *api.Pid
+func Pid(node api.INode, kind, identity string) *api.Pid {
+	name := Name(kind, identity)
+	var nodeId uint64
+	if discovery := node.Discovery(); discovery != nil {
+		nodeId = owner(discovery.GetByKind(kind), name)
+	}
+	if nodeId == 0 {
+		nodeId = node.GetID()
+	}
+	return &api.Pid{NodeId: nodeId, Name: name}
+}
+
+func Name(kind, identity string) string {
+	return namePrefix + kind + "/" + identity
+}
+
+func parseName(name string) (kind, identity string, ok bool) {
+	if !strings.HasPrefix(name, namePrefix) {
+		return "", "", false
+	}
+	kind, identity, ok = strings.Cut(name[len(namePrefix):], "/")
+	return
+}
+
+// Manager
+// @Description: 本节点的虚拟actor激活器
+type Manager struct {
+	sync.Mutex
+	node        api.INode
+	system      api.IActorSystem
+	kinds       map[string]*Kind
+	activations map[string]*api.Pid
+	// pending 上一次激活停止中收到的消息,停止后重新投递
+	pending       map[string][]*api.Message
+	rebalancer    *Rebalancer
+	rebalancerPid *api.Pid
+}
+
+var _ api.IActivator = &Manager{}
+
+// Activate
+// @Description: 本节点拥有该identity时激活,否则返回拥有者节点的地址.其他节点转发来的消息按本节点的拓扑不属于本节点时拒绝,
+// 拓扑不一致期间避免同一identity在两个节点同时激活.上一次激活正在停止时暂存消息,返回nil
+// @receiver m
+// @param name
+// @param message
+// @return *api.Pid
+// @return *api.Error
+func (m *Manager) Activate(name string, message *api.Message) (*api.Pid, *api.Error) {
+	kindName, identity, ok := parseName(name)
+	if !ok {
+		return nil, api.ErrProcessNotExist
+	}
+	kind, ok := m.kinds[kindName]
+	if !ok {
+		return nil, api.ErrGrainKindNotExist
+	}
+	if pid := Pid(m.node, kindName, identity); !m.system.IsLocalPid(pid) {
+		fromLocal := message == nil || message.From == nil || m.system.IsLocalPid(message.From)
+		if fromLocal {
+			return pid, nil
+		}
+		return nil, api.ErrGrainNotOwner
+	}
+	return m.activate(kind, name, identity, message)
+}
+
+func (m *Manager) activate(kind *Kind, name, identity string, message *api.Message) (*api.Pid, *api.Error) {
+	m.Lock()
+	defer m.Unlock()
+	if process := m.system.Find(&api.Pid{NodeId: m.node.GetID(), Name: name}); process != nil {
+		if !process.IsStop() {
+			return process.Pid(), nil
+		}
+		if message == nil {
+			return nil, api.ErrActorStopped
+		}
+		// 停止后Rebalancer收到Terminated时重新投递
+		m.pending[name] = append(m.pending[name], message)
+		return nil, nil
+	}
+	opts := append([]api.ProcessOption{}, kind.Options...)
+	opts = append(opts, api.WithActorName(name))
+	if kind.IdleTimeout > 0 {
+		opts = append(opts, api.WithActorReceiveTimeout(kind.IdleTimeout, true))
+	}
+	pid, err := m.system.Spawn(kind.Producer, &Identity{Kind: kind.Name, Identity: identity}, opts...)
+	if err == api.ErrActorNameExist {
+		return nil, api.ErrActorStopped
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.activations[name] = pid
+	m.watch(pid)
+	zlog.Debug("grain activate", zap.String("name", name), zap.Uint64("uniqId", pid.GetUniqId()))
+	return pid, nil
+}
+
+// watch
+// @Description: 在Rebalancer的协程中监视激活的actor,停止后移除记录
+// @receiver m
+// @param pid
+func (m *Manager) watch(pid *api.Pid) {
+	process := m.system.Find(m.rebalancerPid)
+	if process == nil {
+		return
+	}
+	message := &api.Message{Method: api.ContinuationFuncName, To: m.rebalancerPid}
+	message.SetBody(func() {
+		_ = m.rebalancer.Ctx.Watch(pid)
+	})
+	_ = process.PostMessage(message)
+}
+
+// deactivated
+// @Description: 激活的actor已停止,名字已释放,重新投递停止期间暂存的消息
+// @receiver m
+// @param pid
+func (m *Manager) deactivated(pid *api.Pid) {
+	name := pid.GetName()
+	m.Lock()
+	if exist, ok := m.activations[name]; ok && exist.Key() == pid.Key() {
+		delete(m.activations, name)
+	}
+	pending := m.pending[name]
+	delete(m.pending, name)
+	m.Unlock()
+	to := &api.Pid{NodeId: m.node.GetID(), Name: name}
+	for _, message := range pending {
+		if err := m.system.Forward(to, message); err != nil {
+			_ = message.Respond(&api.RespondMessage{Err: err})
+		}
+	}
+}
+
+// rebalance
+// @Description: 节点变化后停止不再属于本节点的虚拟actor,下次消息在新节点上激活
+// @receiver m
+func (m *Manager) rebalance() {
+	m.Lock()
+	var moved []*api.Pid
+	for name, pid := range m.activations {
+		if m.system.Find(pid) == nil {
+			delete(m.activations, name)
+			continue
+		}
+		kind, identity, _ := parseName(name)
+		if !m.system.IsLocalPid(Pid(m.node, kind, identity)) {
+			delete(m.activations, name)
+			moved = append(moved, pid)
+		}
+	}
+	m.Unlock()
+	for _, pid := range moved {
+		zlog.Info("grain deactivate moved", zap.String("name", pid.GetName()))
+		_ = m.system.Kill(pid)
+	}
+}
+
+// Count
+// @Description: 本节点激活的虚拟actor数量
+func (m *Manager) Count() int {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.activations)
+}
+
+// Rebalancer
+// @Description: 订阅集群变化
+type Rebalancer struct {
+	api.BuiltinActor
+	manager *Manager
+}
+
+func (r *Rebalancer) OnInit(ctx api.IActorContext) *api.Error {
+	_ = r.BuiltinActor.OnInit(ctx)
+	ctx.AddGroup(api.ClusterUpdateGroup)
+	return nil
+}
+
+// OnTerminated
+// @Description: 激活的actor停止
+func (r *Rebalancer) OnTerminated(terminated *api.Terminated) *api.Error {
+	r.manager.deactivated(terminated.Who)
+	return nil
+}
+
+// OnUpdateClusterGroup
+// @Description: 重新计算归属,不解析拓扑内容
+func (r *Rebalancer) OnUpdateClusterGroup(_ []byte) *api.Error {
+	r.manager.rebalance()
+	return nil
+}
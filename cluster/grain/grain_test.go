@@ -0,0 +1,140 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: grain_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/21 15:10
+ */
+
+package grain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/internal/testnode"
+)
+
+// Counter
+// @Description: 测试用虚拟actor
+type Counter struct {
+	api.BuiltinActor
+	count int
+}
+
+func (c *Counter) Add(delta *int) (*int, *api.Error) {
+	c.count += *delta
+	return &c.count, nil
+}
+
+func (c *Counter) Sleep(ms *int) *api.Error {
+	time.Sleep(time.Duration(*ms) * time.Millisecond)
+	return nil
+}
+
+func add(n api.INode, identity string) (int, *api.Error) {
+	delta, count := 1, 0
+	err := n.System().Call(nil, Pid(n, "counter", identity), "Add", &delta, &count)
+	return count, err
+}
+
+func counterKind(idleTimeout time.Duration) *Kind {
+	return NewKind("counter", func() api.IActor { return new(Counter) }, idleTimeout)
+}
+
+func TestActivate(t *testing.T) {
+	n := testnode.New(t, "grain-activate", 1, testnode.WithTags("counter"))
+	m := Start(n, counterKind(50*time.Millisecond))
+	for want := 1; want <= 2; want++ {
+		if got, err := add(n, "a"); err != nil || got != want {
+			t.Fatalf("add = %d %v", got, err)
+		}
+	}
+	if m.Count() != 1 {
+		t.Fatalf("count = %d", m.Count())
+	}
+	// 空闲停止后移除记录,再次调用重新激活
+	testnode.WaitFor(t, func() bool { return m.Count() == 0 })
+	if got, err := add(n, "a"); err != nil || got != 1 {
+		t.Fatalf("reactivate = %d %v", got, err)
+	}
+
+	delta, count := 1, 0
+	err := n.System().Call(nil, Pid(n, "missing", "a"), "Add", &delta, &count)
+	if err != api.ErrGrainKindNotExist {
+		t.Fatalf("missing kind = %v", err)
+	}
+}
+
+func TestActivateStopped(t *testing.T) {
+	n := testnode.New(t, "grain-stopped", 1, testnode.WithTags("counter"))
+	Start(n, counterKind(0))
+	for i := 0; i < 20; i++ {
+		if _, err := add(n, "a"); err != nil {
+			t.Fatal(err)
+		}
+		process := n.System().Find(Pid(n, "counter", "a"))
+		if process == nil {
+			t.Fatal("grain not activated")
+		}
+		// 停止中的激活不返回ErrActorStopped,重新激活
+		_ = n.System().Kill(process.Pid())
+		if got, err := add(n, "a"); err != nil || got != 1 {
+			t.Fatalf("add after kill = %d %v", got, err)
+		}
+	}
+}
+
+func TestActivateStopping(t *testing.T) {
+	n := testnode.New(t, "grain-stopping", 1, testnode.WithTags("counter"))
+	m := Start(n, counterKind(0))
+	if _, err := add(n, "a"); err != nil {
+		t.Fatal(err)
+	}
+	pid := Pid(n, "counter", "a")
+	process := n.System().Find(pid)
+	ms := 100
+	_ = n.System().Send(nil, pid, "Sleep", &ms)
+	testnode.WaitFor(t, func() bool { return process.Mailbox().Len() == 0 })
+	go func() { _ = n.System().Kill(process.Pid()) }()
+	for !process.IsStop() {
+		time.Sleep(time.Millisecond)
+	}
+	// 停止中收到的消息暂存,停止后在新的激活上处理
+	if got, err := add(n, "a"); err != nil || got != 1 {
+		t.Fatalf("add while stopping = %d %v", got, err)
+	}
+	testnode.WaitFor(t, func() bool { return m.Count() == 1 })
+}
+
+func TestOwner(t *testing.T) {
+	a := testnode.New(t, "grain-owner", 1, testnode.WithTags("counter"))
+	b := testnode.New(t, "grain-owner", 2, testnode.WithTags("counter"))
+	ma, mb := Start(a, counterKind(0)), Start(b, counterKind(0))
+	testnode.WaitFor(t, func() bool {
+		return len(a.Discovery().GetByKind("counter")) == 2 && len(b.Discovery().GetByKind("counter")) == 2
+	})
+	var identity string
+	for i := 0; identity == ""; i++ {
+		if id := fmt.Sprint(i); Pid(a, "counter", id).GetNodeId() == b.GetID() {
+			identity = id
+		}
+	}
+	if got, err := add(a, identity); err != nil || got != 1 {
+		t.Fatalf("add = %d %v", got, err)
+	}
+	if ma.Count() != 0 || mb.Count() != 1 {
+		t.Fatalf("activations = %d %d", ma.Count(), mb.Count())
+	}
+	// 其他节点转发来的消息不在非拥有者节点激活
+	from := &api.Pid{NodeId: b.GetID(), UniqId: 1}
+	to := &api.Pid{NodeId: a.GetID(), Name: Name("counter", identity)}
+	if err := a.System().Call(from, to, "Add", 1, nil); err != api.ErrGrainNotOwner {
+		t.Fatalf("non owner = %v", err)
+	}
+	if ma.Count() != 0 {
+		t.Fatalf("non owner activated %d", ma.Count())
+	}
+}
@@ -0,0 +1,38 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: placement
+ * @Version: 1.0.0
+ * @Date: 2025/1/21 10:30
+ */
+
+package grain
+
+import (
+	"encoding/binary"
+	"github.com/dingqinghui/gas/api"
+	"hash/fnv"
+)
+
+// owner
+// @Description: 最高随机权重(rendezvous)哈希,节点变化时只有离开或新加入节点相关的identity会迁移
+// @param nodes 带有kind标签的节点
+// @param name
+// @return uint64 节点id,没有节点返回0
+func owner(nodes []api.INodeBase, name string) uint64 {
+	var best uint64
+	var bestScore uint64
+	buf := make([]byte, 8)
+	for _, node := range nodes {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(name))
+		binary.BigEndian.PutUint64(buf, node.GetID())
+		_, _ = h.Write(buf)
+		score := h.Sum64()
+		if best == 0 || score > bestScore || (score == bestScore && node.GetID() < best) {
+			best = node.GetID()
+			bestScore = score
+		}
+	}
+	return best
+}
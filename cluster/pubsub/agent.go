@@ -0,0 +1,219 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: agent
+ * @Version: 1.0.0
+ * @Date: 2025/1/26 10:40
+ */
+
+package pubsub
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+)
+
+type subscriber struct {
+	pid    *api.Pid
+	method string
+}
+
+// Agent
+// @Description: 每个节点一个,记录本节点的订阅者和其他节点订阅的主题,发布时每个节点只发送一次
+type Agent struct {
+	api.BuiltinActor
+	// local 主题->本节点订阅者
+	local map[string]map[string]*subscriber
+	// remote 主题->订阅的其他节点
+	remote map[string]map[uint64]bool
+	// nodes 已知的其他节点
+	nodes map[uint64]bool
+}
+
+func (a *Agent) OnInit(ctx api.IActorContext) *api.Error {
+	_ = a.BuiltinActor.OnInit(ctx)
+	a.local = make(map[string]map[string]*subscriber)
+	a.remote = make(map[string]map[uint64]bool)
+	a.nodes = make(map[uint64]bool)
+	ctx.AddGroup(api.ClusterUpdateGroup)
+	// 新启动的节点向其他节点要订阅信息
+	for _, nodeId := range a.aliveNodes() {
+		a.nodes[nodeId] = true
+		a.sync(nodeId, true)
+	}
+	return nil
+}
+
+func (a *Agent) Subscribe(request *SubscribeRequest) *api.Error {
+	if request.Topic == "" || request.Method == "" || !a.Ctx.System().IsLocalPid(request.Pid) {
+		return api.ErrInvalidActorMessage
+	}
+	subscribers, ok := a.local[request.Topic]
+	if !ok {
+		subscribers = make(map[string]*subscriber)
+		a.local[request.Topic] = subscribers
+		a.announce("NodeSubscribe", request.Topic)
+	}
+	if !a.subscribed(request.Pid) {
+		if err := a.Ctx.Watch(request.Pid); err != nil {
+			return err
+		}
+	}
+	subscribers[request.Pid.Key()] = &subscriber{pid: request.Pid, method: request.Method}
+	return nil
+}
+
+func (a *Agent) Unsubscribe(request *SubscribeRequest) *api.Error {
+	a.remove(request.Topic, request.Pid)
+	if !a.subscribed(request.Pid) {
+		_ = a.Ctx.Unwatch(request.Pid)
+	}
+	return nil
+}
+
+// OnTerminated
+// @Description: 订阅者停止,取消它的所有订阅
+func (a *Agent) OnTerminated(terminated *api.Terminated) *api.Error {
+	for topic := range a.local {
+		a.remove(topic, terminated.Who)
+	}
+	return nil
+}
+
+// Publish
+// @Description: 本节点发布,有订阅者的其他节点各发送一次
+func (a *Agent) Publish(request *PublishRequest) *api.Error {
+	for nodeId := range a.remote[request.Topic] {
+		if err := a.Ctx.Send(agentPid(nodeId), "Deliver", request); err != nil {
+			zlog.Warn("pubsub publish", zap.String("topic", request.Topic), zap.Uint64("nodeId", nodeId), zap.Error(err))
+		}
+	}
+	return a.Deliver(request)
+}
+
+// Deliver
+// @Description: 分发给本节点的订阅者
+func (a *Agent) Deliver(request *PublishRequest) *api.Error {
+	for _, sub := range a.local[request.Topic] {
+		message := api.BuildInnerMessage(request.From, sub.pid, sub.method, request.Data)
+		_ = a.Ctx.System().PostMessage(sub.pid, message)
+	}
+	return nil
+}
+
+func (a *Agent) NodeSubscribe(request *NodeTopic) *api.Error {
+	nodes, ok := a.remote[request.Topic]
+	if !ok {
+		nodes = make(map[uint64]bool)
+		a.remote[request.Topic] = nodes
+	}
+	nodes[request.NodeId] = true
+	return nil
+}
+
+func (a *Agent) NodeUnsubscribe(request *NodeTopic) *api.Error {
+	a.removeNode(request.Topic, request.NodeId)
+	return nil
+}
+
+// Sync
+// @Description: 用对方上报的主题替换该节点原有的订阅
+func (a *Agent) Sync(request *NodeTopics) *api.Error {
+	for topic := range a.remote {
+		a.removeNode(topic, request.NodeId)
+	}
+	for _, topic := range request.Topics {
+		_ = a.NodeSubscribe(&NodeTopic{NodeId: request.NodeId, Topic: topic})
+	}
+	if request.Reply {
+		a.sync(request.NodeId, false)
+	}
+	return nil
+}
+
+// OnUpdateClusterGroup
+// @Description: 清理离开节点的订阅,新加入的节点发送本节点订阅的主题
+func (a *Agent) OnUpdateClusterGroup(_ []byte) *api.Error {
+	alive := make(map[uint64]bool)
+	for _, nodeId := range a.aliveNodes() {
+		alive[nodeId] = true
+		if !a.nodes[nodeId] {
+			a.sync(nodeId, false)
+		}
+	}
+	for topic := range a.remote {
+		for nodeId := range a.remote[topic] {
+			if !alive[nodeId] {
+				a.removeNode(topic, nodeId)
+			}
+		}
+	}
+	a.nodes = alive
+	return nil
+}
+
+func (a *Agent) sync(nodeId uint64, reply bool) {
+	request := &NodeTopics{NodeId: a.Ctx.Self().GetNodeId(), Reply: reply}
+	for topic := range a.local {
+		request.Topics = append(request.Topics, topic)
+	}
+	_ = a.Ctx.Send(agentPid(nodeId), "Sync", request)
+}
+
+// announce
+// @Description: 本节点第一个订阅或最后一个取消订阅时通知其他节点
+func (a *Agent) announce(method, topic string) {
+	request := &NodeTopic{NodeId: a.Ctx.Self().GetNodeId(), Topic: topic}
+	for nodeId := range a.nodes {
+		_ = a.Ctx.Send(agentPid(nodeId), method, request)
+	}
+}
+
+func (a *Agent) remove(topic string, pid *api.Pid) {
+	subscribers, ok := a.local[topic]
+	if !ok {
+		return
+	}
+	delete(subscribers, pid.Key())
+	if len(subscribers) == 0 {
+		delete(a.local, topic)
+		a.announce("NodeUnsubscribe", topic)
+	}
+}
+
+func (a *Agent) removeNode(topic string, nodeId uint64) {
+	nodes, ok := a.remote[topic]
+	if !ok {
+		return
+	}
+	delete(nodes, nodeId)
+	if len(nodes) == 0 {
+		delete(a.remote, topic)
+	}
+}
+
+func (a *Agent) subscribed(pid *api.Pid) bool {
+	for _, subscribers := range a.local {
+		if _, ok := subscribers[pid.Key()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// aliveNodes
+// @Description: 其他存活节点
+func (a *Agent) aliveNodes() []uint64 {
+	discovery := a.Ctx.System().Node().Discovery()
+	if discovery == nil {
+		return nil
+	}
+	var result []uint64
+	for _, node := range discovery.GetAll() {
+		if node.GetID() != a.Ctx.Self().GetNodeId() {
+			result = append(result, node.GetID())
+		}
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: pubsub
+ * @Version: 1.0.0
+ * @Date: 2025/1/26 10:10
+ */
+
+package pubsub
+
+import (
+	"github.com/dingqinghui/gas/api"
+)
+
+const agentName = "pubsub/agent"
+
+type (
+	// SubscribeRequest
+	// @Description: 本节点actor订阅主题,收到发布时调用Method
+	SubscribeRequest struct {
+		Topic  string
+		Pid    *api.Pid
+		Method string
+	}
+
+	// PublishRequest
+	// @Description: 发布的消息,Data为序列化后的消息体
+	PublishRequest struct {
+		Topic string
+		From  *api.Pid
+		Data  []byte
+	}
+
+	// NodeTopic
+	// @Description: 节点开始或取消订阅主题
+	NodeTopic struct {
+		NodeId uint64
+		Topic  string
+	}
+
+	// NodeTopics
+	// @Description: 节点订阅的全部主题
+	NodeTopics struct {
+		NodeId uint64
+		Topics []string
+		// Reply 是否需要对方回复自己订阅的主题
+		Reply bool
+	}
+)
+
+// Start
+// @Description: 启动本节点的发布订阅代理,每个节点都需要启动
+// @param node
+// @return *api.Error
+func Start(node api.INode) *api.Error {
+	_, err := node.System().Spawn(func() api.IActor { return &Agent{} }, nil, api.WithActorName(agentName))
+	return err
+}
+
+// Subscribe
+// @Description: 订阅集群主题,actor停止后自动取消
+// @param node
+// @param pid 本节点的actor
+// @param topic
+// @param method 收到消息时调用的方法,参数为发布的消息类型
+// @return *api.Error
+func Subscribe(node api.INode, pid *api.Pid, topic, method string) *api.Error {
+	request := &SubscribeRequest{Topic: topic, Pid: pid, Method: method}
+	return node.System().Call(nil, agentPid(node.GetID()), "Subscribe", request, nil)
+}
+
+// Unsubscribe
+// @Description: 取消订阅
+// @param node
+// @param pid
+// @param topic
+// @return *api.Error
+func Unsubscribe(node api.INode, pid *api.Pid, topic string) *api.Error {
+	request := &SubscribeRequest{Topic: topic, Pid: pid}
+	return node.System().Call(nil, agentPid(node.GetID()), "Unsubscribe", request, nil)
+}
+
+// Publish
+// @Description: 发布到集群主题,每个有订阅者的节点收到一次后在本地分发
+// @param node
+// @param from 可以为nil
+// @param topic
+// @param msg
+// @return *api.Error
+func Publish(node api.INode, from *api.Pid, topic string, msg interface{}) *api.Error {
+	data, err := node.Serializer().Marshal(msg)
+	if err != nil {
+		return api.ErrJsonPack
+	}
+	request := &PublishRequest{Topic: topic, From: from, Data: data}
+	return node.System().Send(from, agentPid(node.GetID()), "Publish", request)
+}
+
+func agentPid(nodeId uint64) *api.Pid {
+	return &api.Pid{NodeId: nodeId, Name: agentName}
+}
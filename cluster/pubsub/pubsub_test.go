@@ -0,0 +1,109 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: pubsub_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/26 15:30
+ */
+
+package pubsub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/internal/testnode"
+)
+
+type Msg struct {
+	Text string
+}
+
+// Chat
+// @Description: 测试用订阅者
+type Chat struct {
+	api.BuiltinActor
+	got chan string
+}
+
+func (c *Chat) OnRoom(msg *Msg) *api.Error {
+	c.got <- msg.Text
+	return nil
+}
+
+// newNode
+// @Description: 启动pubsub的测试节点
+func newNode(t *testing.T, cluster string, id uint64) api.INode {
+	n := testnode.New(t, cluster, id)
+	if err := Start(n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func subscribe(t *testing.T, n api.INode) (*api.Pid, chan string) {
+	got := make(chan string, 16)
+	pid, err := n.System().Spawn(func() api.IActor { return &Chat{got: got} }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Subscribe(n, pid, "room", "OnRoom"); err != nil {
+		t.Fatal(err)
+	}
+	return pid, got
+}
+
+func expectNone(t *testing.T, got chan string) {
+	select {
+	case text := <-got:
+		t.Fatalf("unexpected %s", text)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPublish(t *testing.T) {
+	a := newNode(t, "pubsub", 1)
+	b := newNode(t, "pubsub", 2)
+	local, localGot := subscribe(t, a)
+	remote, remoteGot := subscribe(t, b)
+
+	// 订阅信息异步同步到其他节点,重复发布直到收到
+	deadline := time.After(3 * time.Second)
+	for received := false; !received; {
+		_ = Publish(a, nil, "room", &Msg{Text: "hi"})
+		select {
+		case <-remoteGot:
+			received = true
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("remote subscriber not reached")
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	for len(localGot) > 0 || len(remoteGot) > 0 {
+		select {
+		case <-localGot:
+		case <-remoteGot:
+		}
+	}
+
+	if err := Unsubscribe(b, remote, "room"); err != nil {
+		t.Fatal(err)
+	}
+	_ = Publish(a, nil, "room", &Msg{Text: "local"})
+	if text := <-localGot; text != "local" {
+		t.Fatalf("local = %s", text)
+	}
+	expectNone(t, remoteGot)
+
+	// 订阅者停止后自动取消,不再产生死信
+	_ = a.System().Kill(local)
+	time.Sleep(50 * time.Millisecond)
+	count := a.System().DeadLetter().Count()
+	_ = Publish(a, nil, "room", &Msg{Text: "stopped"})
+	time.Sleep(50 * time.Millisecond)
+	if a.System().DeadLetter().Count() != count {
+		t.Fatal("published to stopped subscriber")
+	}
+}
@@ -0,0 +1,144 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: agent
+ * @Version: 1.0.0
+ * @Date: 2025/1/25 11:00
+ */
+
+package registry
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+)
+
+// Agent
+// @Description: 每个节点一个,记录本节点注册的名字,监视注册的actor,停止后从注册表移除
+type Agent struct {
+	api.BuiltinActor
+	store *api.Pid
+	names map[string]*api.Pid
+}
+
+func (a *Agent) OnInit(ctx api.IActorContext) *api.Error {
+	_ = a.BuiltinActor.OnInit(ctx)
+	a.names = make(map[string]*api.Pid)
+	return nil
+}
+
+// Register
+// @Description: 异步提交到注册表,确认后再回复调用方,不阻塞agent
+func (a *Agent) Register(entry *Entry) *api.Error {
+	if entry.Name == "" || !a.Ctx.System().IsLocalPid(entry.Pid) || a.Ctx.System().Find(entry.Pid) == nil {
+		return api.ErrInvalidPid
+	}
+	respond := a.deferRespond()
+	return a.callStore("Register", entry, respond, func(err *api.Error) *api.Error {
+		if err != nil {
+			return err
+		}
+		a.names[entry.Name] = entry.Pid
+		return a.Ctx.Watch(entry.Pid)
+	})
+}
+
+func (a *Agent) Unregister(entry *Entry) *api.Error {
+	pid, ok := a.names[entry.Name]
+	if !ok {
+		return api.ErrActorNameNotExist
+	}
+	delete(a.names, entry.Name)
+	if !a.registered(pid) {
+		_ = a.Ctx.Unwatch(pid)
+	}
+	respond := a.deferRespond()
+	return a.callStore("Unregister", &Entry{Name: entry.Name, Pid: pid}, respond, nil)
+}
+
+// deferRespond
+// @Description: 当前调用改为稍后回复,方法返回时不再回复调用方
+// @receiver a
+// @return api.RespondFun 调用方的回复函数,Send时为nil
+func (a *Agent) deferRespond() api.RespondFun {
+	msg := a.Ctx.Message()
+	respond := msg.Responder()
+	msg.SetRespond(nil)
+	return respond
+}
+
+// callStore
+// @Description: 异步调用注册表,结果在agent的邮箱中处理后回复调用方
+// @receiver a
+// @param method
+// @param entry
+// @param respond 调用方的回复函数
+// @param done 注册表的结果,返回回复调用方的错误
+// @return *api.Error
+func (a *Agent) callStore(method string, entry *Entry, respond api.RespondFun, done func(err *api.Error) *api.Error) *api.Error {
+	reply := func(err *api.Error) {
+		if respond != nil {
+			_ = respond(&api.RespondMessage{Err: err})
+		}
+	}
+	err := a.Ctx.CallAsync(a.store, method, entry, nil, func(_ interface{}, err *api.Error) {
+		if done != nil {
+			err = done(err)
+		}
+		reply(err)
+	})
+	if err != nil {
+		reply(err)
+	}
+	return err
+}
+
+// OnTerminated
+// @Description: 注册的actor停止,移除它的所有名字
+func (a *Agent) OnTerminated(terminated *api.Terminated) *api.Error {
+	for name, pid := range a.names {
+		if pid.Key() != terminated.Who.Key() {
+			continue
+		}
+		delete(a.names, name)
+		_ = a.Ctx.Send(a.store, "Unregister", &Entry{Name: name, Pid: pid})
+	}
+	return nil
+}
+
+// Resync
+// @Description: 注册表重建,全量上报本节点的名字
+func (a *Agent) Resync(_ *ResyncRequest) *api.Error {
+	request := &SyncRequest{NodeId: a.Ctx.Self().GetNodeId()}
+	for name, pid := range a.names {
+		request.Entries = append(request.Entries, &Entry{Name: name, Pid: pid})
+	}
+	reply := new(SyncReply)
+	return a.Ctx.CallAsync(a.store, "Sync", request, reply, func(_ interface{}, err *api.Error) {
+		if err != nil {
+			zlog.Error("registry resync", zap.Error(err))
+			return
+		}
+		for _, name := range reply.Rejected {
+			zlog.Warn("registry name conflict", zap.String("name", name))
+			pid, ok := a.names[name]
+			if !ok {
+				continue
+			}
+			delete(a.names, name)
+			if !a.registered(pid) {
+				_ = a.Ctx.Unwatch(pid)
+			}
+		}
+	})
+}
+
+func (a *Agent) registered(pid *api.Pid) bool {
+	for _, v := range a.names {
+		if v.Key() == pid.Key() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: registry
+ * @Version: 1.0.0
+ * @Date: 2025/1/25 10:10
+ */
+
+package registry
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/cluster/singleton"
+)
+
+const (
+	storeName = "registry"
+	agentName = "registry/agent"
+)
+
+type (
+	// Entry
+	// @Description: 名字和actor地址
+	Entry struct {
+		Name string
+		Pid  *api.Pid
+	}
+
+	// SyncRequest
+	// @Description: 节点全量上报本节点注册的名字
+	SyncRequest struct {
+		NodeId  uint64
+		Entries []*Entry
+	}
+
+	// SyncReply
+	// @Description: 与其他节点冲突被拒绝的名字
+	SyncReply struct {
+		Rejected []string
+	}
+
+	ResyncRequest struct{}
+)
+
+// Start
+// @Description: 启动集群名字注册表,每个节点都需要启动,注册表由集群单例保存,各节点记录自己注册的名字
+// @param node
+// @return *api.Error
+func Start(node api.INode) *api.Error {
+	system := node.System()
+	proxy, err := singleton.Start(node, &singleton.Settings{
+		Name:     storeName,
+		Producer: func() api.IActor { return &Store{} },
+	})
+	if err != nil {
+		return err
+	}
+	producer := func() api.IActor { return &Agent{store: proxy} }
+	_, err = system.Spawn(producer, nil, api.WithActorName(agentName))
+	return err
+}
+
+// Register
+// @Description: 集群范围内注册名字,名字已被其他actor注册返回api.ErrActorNameExist,actor停止或所在节点离开后自动移除
+// @param node
+// @param name
+// @param pid 本节点的actor
+// @return *api.Error
+func Register(node api.INode, name string, pid *api.Pid) *api.Error {
+	return node.System().Call(nil, agentPid(node), "Register", &Entry{Name: name, Pid: pid}, nil)
+}
+
+// Unregister
+// @Description: 移除本节点注册的名字
+// @param node
+// @param name
+// @return *api.Error
+func Unregister(node api.INode, name string) *api.Error {
+	return node.System().Call(nil, agentPid(node), "Unregister", &Entry{Name: name}, nil)
+}
+
+// Lookup
+// @Description: 查询名字对应的actor地址
+// @param node
+// @param name
+// @return *api.Pid
+// @return *api.Error 不存在返回api.ErrActorNameNotExist
+func Lookup(node api.INode, name string) (*api.Pid, *api.Error) {
+	pid := new(api.Pid)
+	if err := node.System().Call(nil, singleton.ProxyPid(node, storeName), "Lookup", &Entry{Name: name}, pid); err != nil {
+		return nil, err
+	}
+	return pid, nil
+}
+
+func agentPid(node api.INode) *api.Pid {
+	return &api.Pid{NodeId: node.GetID(), Name: agentName}
+}
@@ -0,0 +1,81 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: registry_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/25 15:40
+ */
+
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/internal/testnode"
+)
+
+// Guild
+// @Description: 测试用actor
+type Guild struct {
+	api.BuiltinActor
+}
+
+func (g *Guild) Ping(v *int) (*int, *api.Error) { return v, nil }
+
+// newNode
+// @Description: 启动registry的测试节点
+func newNode(t *testing.T, cluster string, id uint64) api.INode {
+	n := testnode.New(t, cluster, id)
+	n.System().SetTimeout(3 * time.Second)
+	if err := Start(n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func spawn(t *testing.T, n api.INode) *api.Pid {
+	pid, err := n.System().Spawn(func() api.IActor { return new(Guild) }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
+
+func TestRegistry(t *testing.T) {
+	a := newNode(t, "registry", 1)
+	b := newNode(t, "registry", 2)
+	testnode.WaitFor(t, func() bool { return len(a.Discovery().GetAll()) == 2 && len(b.Discovery().GetAll()) == 2 })
+
+	guild := spawn(t, a)
+	if err := Register(a, "guild", guild); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(b, "guild", spawn(t, b)); err != api.ErrActorNameExist {
+		t.Fatalf("duplicate register = %v", err)
+	}
+	pid, err := Lookup(b, "guild")
+	if err != nil || pid.Key() != guild.Key() {
+		t.Fatalf("lookup = %v %v", pid, err)
+	}
+	if err := Unregister(a, "guild"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lookup(b, "guild"); err != api.ErrActorNameNotExist {
+		t.Fatalf("lookup after unregister = %v", err)
+	}
+
+	// actor停止后自动移除
+	if err := Register(a, "guild", guild); err != nil {
+		t.Fatal(err)
+	}
+	_ = a.System().Kill(guild)
+	testnode.WaitFor(t, func() bool {
+		_, err := Lookup(b, "guild")
+		return err == api.ErrActorNameNotExist
+	})
+	if err := Register(b, "guild", spawn(t, b)); err != nil {
+		t.Fatalf("register after stop = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: store
+ * @Version: 1.0.0
+ * @Date: 2025/1/25 10:35
+ */
+
+package registry
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+)
+
+// Store
+// @Description: 集群单例,保存所有名字,启动时通知各节点重新上报
+type Store struct {
+	api.BuiltinActor
+	names map[string]*api.Pid
+}
+
+func (s *Store) OnInit(ctx api.IActorContext) *api.Error {
+	_ = s.BuiltinActor.OnInit(ctx)
+	s.names = make(map[string]*api.Pid)
+	ctx.AddGroup(api.ClusterUpdateGroup)
+	for _, nodeId := range aliveNodes(ctx.System().Node()) {
+		_ = ctx.Send(&api.Pid{NodeId: nodeId, Name: agentName}, "Resync", &ResyncRequest{})
+	}
+	return nil
+}
+
+func (s *Store) Register(entry *Entry) *api.Error {
+	if exist, ok := s.names[entry.Name]; ok && exist.Key() != entry.Pid.Key() {
+		return api.ErrActorNameExist
+	}
+	s.names[entry.Name] = entry.Pid
+	return nil
+}
+
+// Unregister
+// @Description: 只移除地址一致的名字,避免误删其他actor重新注册的名字
+func (s *Store) Unregister(entry *Entry) *api.Error {
+	if exist, ok := s.names[entry.Name]; ok && exist.Key() == entry.Pid.Key() {
+		delete(s.names, entry.Name)
+	}
+	return nil
+}
+
+func (s *Store) Lookup(entry *Entry) (*api.Pid, *api.Error) {
+	pid, ok := s.names[entry.Name]
+	if !ok {
+		return nil, api.ErrActorNameNotExist
+	}
+	return pid, nil
+}
+
+// Sync
+// @Description: 用节点上报的名字替换该节点原有的名字
+func (s *Store) Sync(request *SyncRequest) (*SyncReply, *api.Error) {
+	for name, pid := range s.names {
+		if pid.GetNodeId() == request.NodeId {
+			delete(s.names, name)
+		}
+	}
+	reply := new(SyncReply)
+	for _, entry := range request.Entries {
+		if err := s.Register(entry); err != nil {
+			reply.Rejected = append(reply.Rejected, entry.Name)
+		}
+	}
+	return reply, nil
+}
+
+// OnUpdateClusterGroup
+// @Description: 移除已离开节点的名字
+func (s *Store) OnUpdateClusterGroup(_ []byte) *api.Error {
+	alive := make(map[uint64]bool)
+	for _, nodeId := range aliveNodes(s.Ctx.System().Node()) {
+		alive[nodeId] = true
+	}
+	for name, pid := range s.names {
+		if !alive[pid.GetNodeId()] {
+			delete(s.names, name)
+			zlog.Info("registry remove left node name", zap.String("name", name), zap.Uint64("nodeId", pid.GetNodeId()))
+		}
+	}
+	return nil
+}
+
+func aliveNodes(node api.INode) []uint64 {
+	discovery := node.Discovery()
+	if discovery == nil {
+		return []uint64{node.GetID()}
+	}
+	var result []uint64
+	for _, n := range discovery.GetAll() {
+		result = append(result, n.GetID())
+	}
+	return result
+}
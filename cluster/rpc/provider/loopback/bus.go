@@ -0,0 +1,182 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: bus
+ * @Version: 1.0.0
+ * @Date: 2025/1/30 10:20
+ */
+
+package loopback
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	busLock sync.Mutex
+	buses   = make(map[string]*Bus)
+)
+
+// GetBus
+// @Description: 进程内按名字共享的消息总线,同一进程中的多个节点使用相同名字即可互相rpc
+// @param name
+// @return *Bus
+func GetBus(name string) *Bus {
+	busLock.Lock()
+	defer busLock.Unlock()
+	b, ok := buses[name]
+	if !ok {
+		b = &Bus{
+			subs:       make(map[string]map[*Transport]api.RpcProcessHandler),
+			partitions: make(map[link]struct{}),
+			rand:       rand.New(rand.NewSource(1)),
+		}
+		buses[name] = b
+	}
+	return b
+}
+
+type link struct {
+	from, to uint64
+}
+
+// Bus
+// @Description: 进程内消息总线,可注入延迟、丢包和网络分区用于测试超时和重试
+type Bus struct {
+	sync.RWMutex
+	subs       map[string]map[*Transport]api.RpcProcessHandler
+	latency    time.Duration
+	dropRate   float64
+	partitions map[link]struct{}
+	rand       *rand.Rand
+}
+
+// SetLatency
+// @Description: 每条消息(包括回复)的传输延迟
+// @receiver b
+// @param latency
+func (b *Bus) SetLatency(latency time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+	b.latency = latency
+}
+
+// SetDropRate
+// @Description: 丢包率,取值[0,1],丢弃的请求由调用方超时返回
+// @receiver b
+// @param rate
+func (b *Bus) SetDropRate(rate float64) {
+	b.Lock()
+	defer b.Unlock()
+	b.dropRate = rate
+}
+
+// SetSeed
+// @Description: 设置丢包随机数种子,相同种子和相同消息顺序得到相同的丢包结果
+// @receiver b
+// @param seed
+func (b *Bus) SetSeed(seed int64) {
+	b.Lock()
+	defer b.Unlock()
+	b.rand = rand.New(rand.NewSource(seed))
+}
+
+// Partition
+// @Description: 断开两个节点之间的双向通信
+// @receiver b
+// @param a
+// @param c
+func (b *Bus) Partition(a, c uint64) {
+	b.Lock()
+	defer b.Unlock()
+	b.partitions[link{from: a, to: c}] = struct{}{}
+	b.partitions[link{from: c, to: a}] = struct{}{}
+}
+
+// Heal
+// @Description: 恢复两个节点之间的通信
+// @receiver b
+// @param a
+// @param c
+func (b *Bus) Heal(a, c uint64) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.partitions, link{from: a, to: c})
+	delete(b.partitions, link{from: c, to: a})
+}
+
+// Reset
+// @Description: 清除所有故障注入
+// @receiver b
+func (b *Bus) Reset() {
+	b.Lock()
+	defer b.Unlock()
+	b.latency = 0
+	b.dropRate = 0
+	b.partitions = make(map[link]struct{})
+}
+
+// route
+// @Description: 判断一条消息能否从from发往to,能则返回传输延迟
+// @receiver b
+// @param from
+// @param to
+// @return time.Duration
+// @return bool
+func (b *Bus) route(from, to uint64) (time.Duration, bool) {
+	b.Lock()
+	defer b.Unlock()
+	if _, ok := b.partitions[link{from: from, to: to}]; ok {
+		return 0, false
+	}
+	if b.dropRate > 0 && b.rand.Float64() < b.dropRate {
+		return 0, false
+	}
+	return b.latency, true
+}
+
+func (b *Bus) subscribe(topic string, t *Transport, process api.RpcProcessHandler) {
+	b.Lock()
+	defer b.Unlock()
+	dict, ok := b.subs[topic]
+	if !ok {
+		dict = make(map[*Transport]api.RpcProcessHandler)
+		b.subs[topic] = dict
+	}
+	dict[t] = process
+}
+
+func (b *Bus) unsubscribeAll(t *Transport) {
+	b.Lock()
+	defer b.Unlock()
+	for topic, dict := range b.subs {
+		delete(dict, t)
+		if len(dict) == 0 {
+			delete(b.subs, topic)
+		}
+	}
+}
+
+type subscriber struct {
+	transport *Transport
+	process   api.RpcProcessHandler
+}
+
+// subscribers
+// @Description: 订阅了topic的所有传输端,按节点id排序保证丢包结果可复现
+func (b *Bus) subscribers(topic string) []*subscriber {
+	b.RLock()
+	defer b.RUnlock()
+	result := make([]*subscriber, 0, len(b.subs[topic]))
+	for t, process := range b.subs[topic] {
+		result = append(result, &subscriber{transport: t, process: process})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].transport.nodeId < result[j].transport.nodeId
+	})
+	return result
+}
@@ -0,0 +1,126 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: loopback_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/30 15:10
+ */
+
+package loopback_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/cluster/rpc/provider/loopback"
+	"github.com/dingqinghui/gas/internal/testnode"
+)
+
+func newTransport(t *testing.T, bus string, nodeId uint64) *loopback.Transport {
+	transport := loopback.New(bus, nodeId).(*loopback.Transport)
+	t.Cleanup(func() { _ = transport.Stop() })
+	name := strconv.FormatUint(nodeId, 10)
+	transport.Subscribe("node."+name, func(_ string, data []byte, respond api.RpcRespondHandler) {
+		if respond != nil {
+			_ = respond(append([]byte(name+":"), data...))
+		}
+	})
+	return transport
+}
+
+func TestTransport(t *testing.T) {
+	a := newTransport(t, "loopback-transport", 1)
+	b := newTransport(t, "loopback-transport", 2)
+	rsp, err := a.Call("node.2", []byte("q"), time.Second)
+	if err != nil || string(rsp) != "2:q" {
+		t.Fatalf("call = %s %v", rsp, err)
+	}
+
+	bus := a.Bus()
+	bus.SetLatency(50 * time.Millisecond)
+	if _, err = a.Call("node.2", nil, 60*time.Millisecond); err != api.ErrRpcTimeout {
+		t.Fatalf("latency = %v", err)
+	}
+	bus.Reset()
+	bus.Partition(1, 2)
+	if _, err = a.Call("node.2", nil, 50*time.Millisecond); err != api.ErrRpcTimeout {
+		t.Fatalf("partition = %v", err)
+	}
+	bus.Heal(1, 2)
+
+	// 相同种子得到相同的丢包结果
+	bus.SetDropRate(0.5)
+	calls := func() (result []bool) {
+		for i := 0; i < 20; i++ {
+			_, err := a.Call("node.2", nil, 20*time.Millisecond)
+			result = append(result, err == nil)
+		}
+		return
+	}
+	bus.SetSeed(7)
+	first := calls()
+	bus.SetSeed(7)
+	for i, ok := range calls() {
+		if ok != first[i] {
+			t.Fatalf("drop not reproducible: %v", first)
+		}
+	}
+	bus.Reset()
+
+	_ = b.Stop()
+	if _, err = a.Call("node.2", nil, 50*time.Millisecond); err != api.ErrRpcNodeNotExist {
+		t.Fatalf("stopped node = %v", err)
+	}
+}
+
+// Echo
+// @Description: 测试用actor
+type Echo struct {
+	api.BuiltinActor
+}
+
+func (e *Echo) Echo(v *string) (*string, *api.Error) { return v, nil }
+
+// newNode
+// @Description: 分区和丢包时调用很快超时
+func newNode(t *testing.T, cluster string, id uint64) api.INode {
+	n := testnode.New(t, cluster, id)
+	n.System().SetTimeout(100 * time.Millisecond)
+	return n
+}
+
+func TestNodes(t *testing.T) {
+	a := newNode(t, "loopback-nodes", 1)
+	b := newNode(t, "loopback-nodes", 2)
+	if _, err := b.System().Spawn(func() api.IActor { return new(Echo) }, nil, api.WithActorName("echo")); err != nil {
+		t.Fatal(err)
+	}
+	echo := &api.Pid{NodeId: b.GetID(), Name: "echo"}
+	call := func() *api.Error {
+		in, out := "hi", ""
+		return a.System().Call(nil, echo, "Echo", &in, &out)
+	}
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := loopback.GetBus("loopback-nodes")
+	bus.Partition(a.GetID(), b.GetID())
+	if err := call(); err == nil {
+		t.Fatal("call across partition succeeded")
+	}
+	bus.Heal(a.GetID(), b.GetID())
+	if err := call(); err != nil {
+		t.Fatalf("call after heal = %v", err)
+	}
+	bus.SetDropRate(1)
+	if err := call(); err == nil {
+		t.Fatal("dropped call succeeded")
+	}
+	bus.Reset()
+	if err := call(); err != nil {
+		t.Fatalf("call after reset = %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: transport
+ * @Version: 1.0.0
+ * @Date: 2025/1/30 10:50
+ */
+
+package loopback
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// New
+// @Description: 进程内rpc传输,消息经Bus投递到同进程的其他节点,不依赖nats
+// @param busName 一般使用集群名
+// @param nodeId 本节点id,用于网络分区判断
+func New(busName string, nodeId uint64) api.IRpcMessageQue {
+	t := &Transport{
+		bus:    GetBus(busName),
+		nodeId: nodeId,
+	}
+	t.Init()
+	return t
+}
+
+type envelope struct {
+	at      time.Time
+	topic   string
+	data    []byte
+	process api.RpcProcessHandler
+	respond api.RpcRespondHandler
+}
+
+type Transport struct {
+	api.BuiltinModule
+	bus    *Bus
+	nodeId uint64
+	lock   sync.Mutex
+	queue  []*envelope
+	wakeup chan struct{}
+	done   chan struct{}
+}
+
+func (t *Transport) Name() string {
+	return "loopback"
+}
+
+func (t *Transport) Init() {
+	t.wakeup = make(chan struct{}, 1)
+	t.done = make(chan struct{})
+	go t.loop()
+}
+
+// Bus
+// @Description: 所在总线,测试中用于注入故障
+func (t *Transport) Bus() *Bus {
+	return t.bus
+}
+
+func (t *Transport) Subscribe(topic string, process api.RpcProcessHandler) {
+	t.bus.subscribe(topic, t, process)
+	zlog.Info("loopback rpc subscribe topic", zap.String("topic", topic))
+}
+
+// Send
+// @Description: 投递给所有订阅者,节点主题只有一个订阅者,广播主题每个节点一个
+// @receiver t
+// @param topic
+// @param data
+// @return *api.Error
+func (t *Transport) Send(topic string, data []byte) *api.Error {
+	for _, sub := range t.bus.subscribers(topic) {
+		dst := sub.transport
+		latency, ok := t.bus.route(t.nodeId, dst.nodeId)
+		if !ok {
+			zlog.Debug("loopback rpc drop", zap.String("topic", topic), zap.Uint64("to", dst.nodeId))
+			continue
+		}
+		dst.enqueue(&envelope{at: time.Now().Add(latency), topic: topic, data: data, process: sub.process})
+	}
+	return nil
+}
+
+// Call
+// @Description: 请求主题的一个订阅者并等待回复,请求或回复被丢弃时超时返回
+// @receiver t
+// @param topic
+// @param data
+// @param timeout
+// @return []byte
+// @return error
+func (t *Transport) Call(topic string, data []byte, timeout time.Duration) ([]byte, error) {
+	subs := t.bus.subscribers(topic)
+	if len(subs) == 0 {
+		return nil, api.ErrRpcNodeNotExist
+	}
+	dst, process := subs[0].transport, subs[0].process
+	ch := make(chan []byte, 1)
+	if latency, ok := t.bus.route(t.nodeId, dst.nodeId); ok {
+		respond := func(rsp []byte) *api.Error {
+			back, ok := t.bus.route(dst.nodeId, t.nodeId)
+			if !ok {
+				return nil
+			}
+			time.AfterFunc(back, func() {
+				select {
+				case ch <- rsp:
+				default:
+				}
+			})
+			return nil
+		}
+		dst.enqueue(&envelope{at: time.Now().Add(latency), topic: topic, data: data, process: process, respond: respond})
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case rsp := <-ch:
+		return rsp, nil
+	case <-timer.C:
+		zlog.Error("loopback rpc request timeout", zap.String("topic", topic))
+		return nil, api.ErrRpcTimeout
+	}
+}
+
+func (t *Transport) enqueue(env *envelope) {
+	t.lock.Lock()
+	t.queue = append(t.queue, env)
+	t.lock.Unlock()
+	select {
+	case t.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// loop
+// @Description: 按到达顺序处理消息,和nats一样同一节点的消息串行处理
+// @receiver t
+func (t *Transport) loop() {
+	for {
+		t.lock.Lock()
+		var env *envelope
+		if len(t.queue) > 0 {
+			env = t.queue[0]
+			t.queue[0] = nil
+			t.queue = t.queue[1:]
+		}
+		t.lock.Unlock()
+		if env == nil {
+			select {
+			case <-t.wakeup:
+				continue
+			case <-t.done:
+				return
+			}
+		}
+		if wait := time.Until(env.at); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-t.done:
+				timer.Stop()
+				return
+			}
+		}
+		env.process(env.topic, env.data, env.respond)
+	}
+}
+
+func (t *Transport) Stop() *api.Error {
+	if err := t.BuiltinStopper.Stop(); err != nil {
+		return err
+	}
+	t.bus.unsubscribeAll(t)
+	close(t.done)
+	zlog.Info("loopback rpc module stop")
+	return nil
+}
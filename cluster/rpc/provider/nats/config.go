@@ -10,11 +10,10 @@ package nats
 
 import "github.com/dingqinghui/gas/api"
 
-func initConfig() *config {
-	if api.GetNode() == nil {
+func initConfig(node api.INode) *config {
+	if node == nil {
 		return nil
 	}
-	node := api.GetNode()
 	c := new(config)
 	vp := node.GetViper().Sub("cluster.nats")
 	c.urls = vp.GetString("urls")
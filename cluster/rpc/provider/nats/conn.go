@@ -16,14 +16,15 @@ import (
 	"time"
 )
 
-func New() api.IRpcMessageQue {
-	c := new(Conn)
+func New(node api.INode) api.IRpcMessageQue {
+	c := &Conn{node: node}
 	c.Init()
 	return c
 }
 
 type Conn struct {
 	api.BuiltinModule
+	node    api.INode
 	rawCon  *nats.Conn
 	msgChan chan *nats.Msg
 	cfg     *config
@@ -34,7 +35,7 @@ func (c *Conn) Name() string {
 }
 
 func (c *Conn) Init() {
-	c.cfg = initConfig()
+	c.cfg = initConfig(c.node)
 	if c.cfg == nil {
 		return
 	}
@@ -89,7 +90,7 @@ func (c *Conn) Send(topic string, data []byte) *api.Error {
 }
 
 func (c *Conn) Subscribe(topic string, process api.RpcProcessHandler) {
-	if api.GetNode() == nil {
+	if c.node == nil {
 		return
 	}
 	_, chanErr := c.rawCon.ChanSubscribe(topic, c.msgChan)
@@ -97,7 +98,7 @@ func (c *Conn) Subscribe(topic string, process api.RpcProcessHandler) {
 		zlog.Error("nats chan subscribe error", zap.Error(chanErr))
 		return
 	}
-	api.GetNode().Submit(func() {
+	c.node.Submit(func() {
 		for msg := range c.msgChan {
 			respond := func(data []byte) *api.Error {
 				if err := msg.Respond(data); err != nil {
@@ -0,0 +1,54 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: config
+ * @Version: 1.0.0
+ * @Date: 2025/1/29 10:05
+ */
+
+package tcp
+
+import (
+	"github.com/spf13/viper"
+	"net"
+	"time"
+)
+
+func initConfig(vp *viper.Viper) *config {
+	c := &config{
+		dialTimeout:  3 * time.Second,
+		writeTimeout: 3 * time.Second,
+	}
+	if vp == nil {
+		return c
+	}
+	// 默认监听节点注册到discovery的地址
+	c.address = vp.GetString("node.address")
+	c.bind = net.JoinHostPort(c.address, vp.GetString("node.port"))
+	sub := vp.Sub("cluster.tcp")
+	if sub == nil {
+		return c
+	}
+	if sub.IsSet("bind") {
+		c.bind = sub.GetString("bind")
+	}
+	c.advertise = sub.GetString("advertise")
+	if sub.IsSet("dialTimeout") {
+		c.dialTimeout = sub.GetDuration("dialTimeout")
+	}
+	if sub.IsSet("writeTimeout") {
+		c.writeTimeout = sub.GetDuration("writeTimeout")
+	}
+	return c
+}
+
+type config struct {
+	bind string
+	// address 节点配置的地址
+	address string
+	// advertise 注册到discovery的地址host:port,为空时使用实际监听的地址,监听0.0.0.0时使用节点地址加实际端口
+	advertise   string
+	dialTimeout time.Duration
+	// writeTimeout 单向消息和回复的写超时,请求使用调用的超时
+	writeTimeout time.Duration
+}
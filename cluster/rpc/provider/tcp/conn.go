@@ -0,0 +1,123 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: conn
+ * @Version: 1.0.0
+ * @Date: 2025/1/29 10:40
+ */
+
+package tcp
+
+import (
+	"bufio"
+	"github.com/dingqinghui/gas/api"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// conn
+// @Description: 节点间的长连接,多个请求复用,写加锁,读在单独协程
+type conn struct {
+	writeLock sync.Mutex
+	raw       net.Conn
+	reader    *bufio.Reader
+	lock      sync.Mutex
+	pending   map[uint64]chan *frame
+	seq       atomic.Uint64
+	closed    atomic.Bool
+}
+
+func newConn(raw net.Conn) *conn {
+	return &conn{
+		raw:     raw,
+		reader:  bufio.NewReader(raw),
+		pending: make(map[uint64]chan *frame),
+	}
+}
+
+// write
+// @Description: 帧过大时直接返回错误;写超时后帧可能只写了一部分,关闭连接
+// @receiver c
+// @param f
+// @param timeout
+// @return *api.Error
+func (c *conn) write(f *frame, timeout time.Duration) *api.Error {
+	if c.closed.Load() {
+		return api.ErrRpcConnClosed
+	}
+	data, err := f.encode()
+	if err != nil {
+		return err
+	}
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	_ = c.raw.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := c.raw.Write(data); err != nil {
+		c.close()
+		return api.ErrRpcConnClosed
+	}
+	return nil
+}
+
+// request
+// @Description: 发送请求等待对应id的回复
+// @receiver c
+// @param subject
+// @param data
+// @param timeout
+// @return []byte
+// @return *api.Error
+func (c *conn) request(subject string, data []byte, timeout time.Duration) ([]byte, *api.Error) {
+	id := c.seq.Add(1)
+	ch := make(chan *frame, 1)
+	c.lock.Lock()
+	c.pending[id] = ch
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, id)
+		c.lock.Unlock()
+	}()
+	if err := c.write(&frame{typ: frameRequest, id: id, subject: subject, data: data}, timeout); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case f, ok := <-ch:
+		if !ok {
+			return nil, api.ErrRpcConnClosed
+		}
+		return f.data, nil
+	case <-timer.C:
+		return nil, api.ErrRpcTimeout
+	}
+}
+
+func (c *conn) reply(f *frame) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if ch, ok := c.pending[f.id]; ok {
+		select {
+		case ch <- f:
+		default:
+		}
+	}
+}
+
+// close
+// @Description: 关闭连接,等待中的请求立即失败
+func (c *conn) close() {
+	if !c.closed.CompareAndSwap(false, true) {
+		return
+	}
+	_ = c.raw.Close()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
@@ -0,0 +1,89 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: frame
+ * @Version: 1.0.0
+ * @Date: 2025/1/29 10:20
+ */
+
+package tcp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"github.com/dingqinghui/gas/api"
+	"io"
+	"math"
+)
+
+const (
+	frameSend byte = iota + 1
+	frameRequest
+	frameReply
+)
+
+const (
+	// 长度(4) 类型(1) 关联id(8) 主题长度(2)
+	frameHeaderSize = 4 + 1 + 8 + 2
+	maxFrameSize    = 64 * 1024 * 1024
+)
+
+var errFrameTooLarge = errors.New("tcp frame too large")
+
+// frame
+// @Description: 连接上的一帧,request和reply通过id关联
+type frame struct {
+	typ     byte
+	id      uint64
+	subject string
+	data    []byte
+}
+
+// encode
+// @Description: 超过对端readFrame的限制时返回错误,不写入连接
+// @receiver f
+// @return []byte
+// @return *api.Error
+func (f *frame) encode() ([]byte, *api.Error) {
+	if len(f.subject) > math.MaxUint16 {
+		return nil, api.ErrRpcFrameTooLarge
+	}
+	size := frameHeaderSize + len(f.subject) + len(f.data)
+	if size > maxFrameSize {
+		return nil, api.ErrRpcFrameTooLarge
+	}
+	buf := make([]byte, size)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(size-4))
+	buf[4] = f.typ
+	binary.BigEndian.PutUint64(buf[5:13], f.id)
+	binary.BigEndian.PutUint16(buf[13:15], uint16(len(f.subject)))
+	copy(buf[frameHeaderSize:], f.subject)
+	copy(buf[frameHeaderSize+len(f.subject):], f.data)
+	return buf, nil
+}
+
+func readFrame(r *bufio.Reader) (*frame, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	size := int(binary.BigEndian.Uint32(header[0:4])) + 4
+	if size > maxFrameSize || size < frameHeaderSize {
+		return nil, errFrameTooLarge
+	}
+	subjectLen := int(binary.BigEndian.Uint16(header[13:15]))
+	if frameHeaderSize+subjectLen > size {
+		return nil, errFrameTooLarge
+	}
+	body := make([]byte, size-frameHeaderSize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return &frame{
+		typ:     header[4],
+		id:      binary.BigEndian.Uint64(header[5:13]),
+		subject: string(body[:subjectLen]),
+		data:    body[subjectLen:],
+	}, nil
+}
@@ -0,0 +1,395 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: transport
+ * @Version: 1.0.0
+ * @Date: 2025/1/29 11:00
+ */
+
+package tcp
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/xerror"
+	"github.com/dingqinghui/gas/zlog"
+	"github.com/duke-git/lancet/v2/convertor"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	nodeTopicPrefix      = "node."
+	broadcastTopicPrefix = "broadcast.tag."
+	// inboxSize 连接上等待处理的消息数,满时暂停读取
+	inboxSize = 1024
+)
+
+// New
+// @Description: 节点间直连的tcp传输,按discovery中节点的地址建立长连接,不依赖消息队列服务
+// @param vp 读取cluster.tcp配置
+// @param discovery 查询节点地址和tag
+func New(vp *viper.Viper, discovery api.IDiscovery) *Transport {
+	t := &Transport{
+		cfg:       initConfig(vp),
+		discovery: discovery,
+	}
+	t.Init()
+	return t
+}
+
+type Transport struct {
+	api.BuiltinModule
+	sync.RWMutex
+	cfg       *config
+	discovery api.IDiscovery
+	listener  net.Listener
+	// address port 注册到discovery的地址
+	address string
+	port    int
+	// outbound 主动建立的连接,按节点id复用
+	outbound map[uint64]*conn
+	dialing  map[uint64]*sync.Mutex
+	inbound  map[*conn]struct{}
+	subs     map[string]api.RpcProcessHandler
+}
+
+func (t *Transport) Name() string {
+	return "tcp"
+}
+
+func (t *Transport) Init() {
+	t.outbound = make(map[uint64]*conn)
+	t.dialing = make(map[uint64]*sync.Mutex)
+	t.inbound = make(map[*conn]struct{})
+	t.subs = make(map[string]api.RpcProcessHandler)
+	listener, err := net.Listen("tcp", t.cfg.bind)
+	if err != nil {
+		zlog.Error("tcp rpc listen", zap.String("bind", t.cfg.bind), zap.Error(err))
+	}
+	xerror.Assert(err)
+	t.listener = listener
+	t.advertise()
+	go t.accept()
+	zlog.Info("tcp rpc listen", zap.String("bind", listener.Addr().String()),
+		zap.String("advertise", net.JoinHostPort(t.address, convertor.ToString(t.port))))
+}
+
+// advertise
+// @Description: 计算注册到discovery的地址,端口为0时使用实际分配的端口
+// @receiver t
+func (t *Transport) advertise() {
+	addr := t.listener.Addr().(*net.TCPAddr)
+	t.address, t.port = addr.IP.String(), addr.Port
+	if addr.IP.IsUnspecified() && t.cfg.address != "" {
+		t.address = t.cfg.address
+	}
+	if t.cfg.advertise == "" {
+		return
+	}
+	host, port, err := net.SplitHostPort(t.cfg.advertise)
+	if err != nil {
+		zlog.Error("tcp rpc advertise", zap.String("advertise", t.cfg.advertise), zap.Error(err))
+		return
+	}
+	t.address = host
+	if p, err := convertor.ToInt(port); err == nil {
+		t.port = int(p)
+	}
+}
+
+// Advertise
+// @Description: 注册到discovery的地址,节点在注册前用它替换配置中的地址和端口
+// @return string
+// @return int
+func (t *Transport) Advertise() (string, int) {
+	return t.address, t.port
+}
+
+// Addr
+// @Description: 实际监听的地址
+func (t *Transport) Addr() string {
+	if t.listener == nil {
+		return ""
+	}
+	return t.listener.Addr().String()
+}
+
+func (t *Transport) Subscribe(topic string, process api.RpcProcessHandler) {
+	t.Lock()
+	defer t.Unlock()
+	t.subs[topic] = process
+	zlog.Info("tcp rpc subscribe topic", zap.String("topic", topic))
+}
+
+// Send
+// @Description: 节点主题发给对应节点,广播主题发给所有带该tag的节点
+// @receiver t
+// @param topic
+// @param data
+// @return *api.Error
+func (t *Transport) Send(topic string, data []byte) *api.Error {
+	if strings.HasPrefix(topic, broadcastTopicPrefix) {
+		return t.broadcast(topic, data)
+	}
+	nodeId, err := parseNodeTopic(topic)
+	if err != nil {
+		return err
+	}
+	return t.send(nodeId, topic, data)
+}
+
+// Call
+// @Description: 请求节点主题并等待回复
+func (t *Transport) Call(topic string, data []byte, timeout time.Duration) ([]byte, error) {
+	nodeId, err := parseNodeTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	if process := t.local(topic); process != nil {
+		return t.callLocal(process, topic, data, timeout)
+	}
+	c, err := t.conn(nodeId)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := c.request(topic, data, timeout)
+	if err != nil {
+		zlog.Error("tcp rpc request", zap.String("topic", topic), zap.Error(err))
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (t *Transport) send(nodeId uint64, topic string, data []byte) *api.Error {
+	if process := t.local(topic); process != nil {
+		process(topic, data, nil)
+		return nil
+	}
+	c, err := t.conn(nodeId)
+	if err != nil {
+		return err
+	}
+	return c.write(&frame{typ: frameSend, subject: topic, data: data}, t.cfg.writeTimeout)
+}
+
+func (t *Transport) broadcast(topic string, data []byte) *api.Error {
+	if t.discovery == nil {
+		return api.ErrDiscoveryProviderIsNil
+	}
+	tag := strings.TrimPrefix(topic, broadcastTopicPrefix)
+	for _, node := range t.discovery.GetByKind(tag) {
+		nodeTopic := nodeTopicPrefix + convertor.ToString(node.GetID())
+		if t.local(nodeTopic) != nil {
+			if process := t.local(topic); process != nil {
+				process(topic, data, nil)
+			}
+			continue
+		}
+		c, err := t.conn(node.GetID())
+		if err != nil {
+			continue
+		}
+		if err = c.write(&frame{typ: frameSend, subject: topic, data: data}, t.cfg.writeTimeout); err != nil {
+			zlog.Warn("tcp rpc broadcast", zap.Uint64("nodeId", node.GetID()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// callLocal
+// @Description: 请求本节点不经过网络
+func (t *Transport) callLocal(process api.RpcProcessHandler, topic string, data []byte, timeout time.Duration) ([]byte, error) {
+	ch := make(chan []byte, 1)
+	process(topic, data, func(rsp []byte) *api.Error {
+		select {
+		case ch <- rsp:
+		default:
+		}
+		return nil
+	})
+	select {
+	case rsp := <-ch:
+		return rsp, nil
+	case <-time.After(timeout):
+		return nil, api.ErrRpcTimeout
+	}
+}
+
+func (t *Transport) local(topic string) api.RpcProcessHandler {
+	t.RLock()
+	defer t.RUnlock()
+	return t.subs[topic]
+}
+
+// conn
+// @Description: 获取到节点的连接,没有则按discovery中的地址建立
+// @receiver t
+// @param nodeId
+// @return *conn
+// @return *api.Error
+func (t *Transport) conn(nodeId uint64) (*conn, *api.Error) {
+	t.Lock()
+	if c, ok := t.outbound[nodeId]; ok && !c.closed.Load() {
+		t.Unlock()
+		return c, nil
+	}
+	dialLock, ok := t.dialing[nodeId]
+	if !ok {
+		dialLock = new(sync.Mutex)
+		t.dialing[nodeId] = dialLock
+	}
+	t.Unlock()
+
+	// 同一节点只建立一个连接
+	dialLock.Lock()
+	defer dialLock.Unlock()
+	t.RLock()
+	c, ok := t.outbound[nodeId]
+	t.RUnlock()
+	if ok && !c.closed.Load() {
+		return c, nil
+	}
+	if t.discovery == nil {
+		return nil, api.ErrDiscoveryProviderIsNil
+	}
+	node := t.discovery.GetById(nodeId)
+	if node == nil {
+		return nil, api.ErrRpcNodeNotExist
+	}
+	addr := net.JoinHostPort(node.GetAddress(), convertor.ToString(node.GetPort()))
+	raw, err := net.DialTimeout("tcp", addr, t.cfg.dialTimeout)
+	if err != nil {
+		zlog.Error("tcp rpc dial", zap.Uint64("nodeId", nodeId), zap.String("addr", addr), zap.Error(err))
+		return nil, api.ErrRpcConnClosed
+	}
+	c = newConn(raw)
+	t.Lock()
+	t.outbound[nodeId] = c
+	t.Unlock()
+	go t.serve(c, func() {
+		t.Lock()
+		defer t.Unlock()
+		if t.outbound[nodeId] == c {
+			delete(t.outbound, nodeId)
+		}
+	})
+	zlog.Info("tcp rpc connect", zap.Uint64("nodeId", nodeId), zap.String("addr", addr))
+	return c, nil
+}
+
+func (t *Transport) accept() {
+	for {
+		raw, err := t.listener.Accept()
+		if err != nil {
+			if t.IsStop() {
+				return
+			}
+			zlog.Error("tcp rpc accept", zap.Error(err))
+			continue
+		}
+		c := newConn(raw)
+		t.Lock()
+		t.inbound[c] = struct{}{}
+		t.Unlock()
+		go t.serve(c, func() {
+			t.Lock()
+			defer t.Unlock()
+			delete(t.inbound, c)
+		})
+	}
+}
+
+// serve
+// @Description: 读取连接上的帧,回复在读协程中唤醒请求方,消息交给处理协程,同一连接上的消息按顺序处理
+// @receiver t
+// @param c
+// @param onClose
+func (t *Transport) serve(c *conn, onClose func()) {
+	inbox := make(chan *frame, inboxSize)
+	go t.work(c, inbox)
+	defer func() {
+		close(inbox)
+		c.close()
+		onClose()
+	}()
+	for {
+		f, err := readFrame(c.reader)
+		if err != nil {
+			if !c.closed.Load() && !t.IsStop() {
+				zlog.Debug("tcp rpc conn closed", zap.String("remote", c.raw.RemoteAddr().String()), zap.Error(err))
+			}
+			return
+		}
+		switch f.typ {
+		case frameReply:
+			c.reply(f)
+		case frameSend, frameRequest:
+			inbox <- f
+		}
+	}
+}
+
+// work
+// @Description: 按顺序处理连接上收到的消息,处理慢时不影响读取回复
+// @receiver t
+// @param c
+// @param inbox
+func (t *Transport) work(c *conn, inbox chan *frame) {
+	for f := range inbox {
+		t.dispatch(c, f)
+	}
+}
+
+func (t *Transport) dispatch(c *conn, f *frame) {
+	process := t.local(f.subject)
+	if process == nil {
+		zlog.Warn("tcp rpc topic not subscribe", zap.String("topic", f.subject))
+		return
+	}
+	var respond api.RpcRespondHandler
+	if f.typ == frameRequest {
+		id, subject := f.id, f.subject
+		respond = func(data []byte) *api.Error {
+			return c.write(&frame{typ: frameReply, id: id, subject: subject, data: data}, t.cfg.writeTimeout)
+		}
+	}
+	process(f.subject, f.data, respond)
+}
+
+func (t *Transport) Stop() *api.Error {
+	if err := t.BuiltinStopper.Stop(); err != nil {
+		return err
+	}
+	if t.listener != nil {
+		_ = t.listener.Close()
+	}
+	t.Lock()
+	conns := make([]*conn, 0, len(t.outbound)+len(t.inbound))
+	for _, c := range t.outbound {
+		conns = append(conns, c)
+	}
+	for c := range t.inbound {
+		conns = append(conns, c)
+	}
+	t.Unlock()
+	for _, c := range conns {
+		c.close()
+	}
+	zlog.Info("tcp rpc module stop")
+	return nil
+}
+
+func parseNodeTopic(topic string) (uint64, *api.Error) {
+	if !strings.HasPrefix(topic, nodeTopicPrefix) {
+		return 0, api.ErrRpcTopic
+	}
+	id, err := convertor.ToInt(strings.TrimPrefix(topic, nodeTopicPrefix))
+	if err != nil {
+		return 0, api.ErrRpcTopic
+	}
+	return uint64(id), nil
+}
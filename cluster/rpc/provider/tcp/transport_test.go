@@ -0,0 +1,183 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: transport_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/29 15:30
+ */
+
+package tcp
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/spf13/viper"
+)
+
+// testDiscovery
+// @Description: 只提供节点查询
+type testDiscovery struct {
+	api.IDiscovery
+	nodes map[uint64]*api.BaseNode
+}
+
+func (d *testDiscovery) GetById(id uint64) api.INodeBase {
+	if node, ok := d.nodes[id]; ok {
+		return node
+	}
+	return nil
+}
+
+func (d *testDiscovery) GetByKind(kind string) (result []api.INodeBase) {
+	for _, node := range d.nodes {
+		for _, tag := range node.Tags {
+			if tag == kind {
+				result = append(result, node)
+			}
+		}
+	}
+	return
+}
+
+func newTransport(t *testing.T, d *testDiscovery, id uint64, bind string) *Transport {
+	vp := viper.New()
+	vp.Set("node.address", "127.0.0.1")
+	vp.Set("cluster.tcp.bind", bind)
+	transport := New(vp, d)
+	t.Cleanup(func() { _ = transport.Stop() })
+	address, port := transport.Advertise()
+	d.nodes[id] = &api.BaseNode{Id: id, Address: address, Port: port, Tags: []string{"x"}}
+	name := strconv.FormatUint(id, 10)
+	transport.Subscribe(nodeTopicPrefix+name, func(_ string, data []byte, respond api.RpcRespondHandler) {
+		if respond != nil {
+			_ = respond(append([]byte(name+":"), data...))
+		}
+	})
+	return transport
+}
+
+func TestTransport(t *testing.T) {
+	d := &testDiscovery{nodes: make(map[uint64]*api.BaseNode)}
+	// 端口0和0.0.0.0注册实际监听的端口和节点地址
+	t1 := newTransport(t, d, 1, "127.0.0.1:0")
+	t2 := newTransport(t, d, 2, "0.0.0.0:0")
+	if node := d.nodes[2]; node.Address != "127.0.0.1" || node.Port == 0 {
+		t.Fatalf("advertise = %s:%d", node.Address, node.Port)
+	}
+	got := make(chan string, 4)
+	for i, transport := range []*Transport{t1, t2} {
+		name := strconv.Itoa(i + 1)
+		transport.Subscribe(broadcastTopicPrefix+"x", func(_ string, data []byte, _ api.RpcRespondHandler) {
+			got <- name + ":" + string(data)
+		})
+	}
+
+	rsp, err := t1.Call("node.2", []byte("q"), time.Second)
+	if err != nil || string(rsp) != "2:q" {
+		t.Fatalf("call = %s %v", rsp, err)
+	}
+	if _, err = t1.Call("node.9", nil, time.Second); err != api.ErrRpcNodeNotExist {
+		t.Fatalf("call unknown node = %v", err)
+	}
+	_ = t2.Send(broadcastTopicPrefix+"x", []byte("all"))
+	seen := map[string]bool{<-got: true, <-got: true}
+	if !seen["1:all"] || !seen["2:all"] {
+		t.Fatalf("broadcast = %v", seen)
+	}
+}
+
+func TestAdvertise(t *testing.T) {
+	vp := viper.New()
+	vp.Set("cluster.tcp.bind", "127.0.0.1:0")
+	vp.Set("cluster.tcp.advertise", "10.0.0.1:9000")
+	transport := New(vp, nil)
+	defer func() { _ = transport.Stop() }()
+	if address, port := transport.Advertise(); address != "10.0.0.1" || port != 9000 {
+		t.Fatalf("advertise = %s:%d", address, port)
+	}
+
+	// 端口被占用时启动失败
+	vp = viper.New()
+	vp.Set("cluster.tcp.bind", transport.Addr())
+	defer func() {
+		if recover() == nil {
+			t.Fatal("listen failure not reported")
+		}
+	}()
+	New(vp, nil)
+}
+
+func TestWriteTimeout(t *testing.T) {
+	// 对端不读取,写超时后关闭连接而不是一直持有写锁
+	local, remote := net.Pipe()
+	defer func() { _ = remote.Close() }()
+	c := newConn(local)
+	start := time.Now()
+	if err := c.write(&frame{typ: frameSend, subject: "node.1"}, 50*time.Millisecond); err != api.ErrRpcConnClosed {
+		t.Fatalf("write = %v", err)
+	}
+	if cost := time.Since(start); cost > time.Second {
+		t.Fatalf("write blocked %v", cost)
+	}
+	if !c.closed.Load() {
+		t.Fatal("conn not closed")
+	}
+}
+
+func TestFrameTooLarge(t *testing.T) {
+	local, remote := net.Pipe()
+	defer func() { _ = remote.Close() }()
+	c := newConn(local)
+	subject := string(make([]byte, math.MaxUint16+1))
+	if err := c.write(&frame{typ: frameSend, subject: subject}, time.Second); err != api.ErrRpcFrameTooLarge {
+		t.Fatalf("write long subject = %v", err)
+	}
+	if err := c.write(&frame{typ: frameSend, subject: "node.1", data: make([]byte, maxFrameSize)}, time.Second); err != api.ErrRpcFrameTooLarge {
+		t.Fatalf("write large frame = %v", err)
+	}
+	// 没有写入任何数据,连接可以继续使用
+	if c.closed.Load() {
+		t.Fatal("conn closed")
+	}
+}
+
+func TestSlowHandler(t *testing.T) {
+	// 处理慢时继续读取连接,发送方不会写超时
+	d := &testDiscovery{nodes: make(map[uint64]*api.BaseNode)}
+	t1 := newTransport(t, d, 1, "127.0.0.1:0")
+	t1.cfg.writeTimeout = 200 * time.Millisecond
+	t2 := newTransport(t, d, 2, "127.0.0.1:0")
+	release, got := make(chan struct{}), make(chan byte, 32)
+	t2.Subscribe(broadcastTopicPrefix+"slow", func(_ string, data []byte, _ api.RpcRespondHandler) {
+		<-release
+		got <- data[0]
+	})
+	d.nodes[2].Tags = []string{"slow"}
+	data := make([]byte, 1024*1024)
+	for i := 0; i < 16; i++ {
+		data[0] = byte(i)
+		if err := t1.Send(broadcastTopicPrefix+"slow", data); err != nil {
+			t.Fatalf("send %d = %v", i, err)
+		}
+	}
+	c, _ := t1.conn(2)
+	if c.closed.Load() {
+		t.Fatal("conn closed by write timeout")
+	}
+	close(release)
+	for i := 0; i < 16; i++ {
+		select {
+		case v := <-got:
+			if v != byte(i) {
+				t.Fatalf("message %d out of order: %d", i, v)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("message %d not handled", i)
+		}
+	}
+}
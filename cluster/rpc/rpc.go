@@ -18,23 +18,25 @@ import (
 	"time"
 )
 
-func New(msgque api.IRpcMessageQue) api.IRpc {
-	if api.GetNode() == nil {
+func New(node api.INode, msgque api.IRpcMessageQue) api.IRpc {
+	if node == nil {
 		return nil
 	}
 	r := new(Rpc)
+	r.node = node
 	r.msgque = msgque
-	api.GetNode().AddModule(r)
+	node.AddModule(r)
 	return r
 }
 
 type Rpc struct {
 	api.BuiltinModule
+	node   api.INode
 	msgque api.IRpcMessageQue
 }
 
 func (r *Rpc) Run() {
-	if api.GetNode() == nil {
+	if r.node == nil {
 		return
 	}
 	process := func(subj string, data []byte, respondFun api.RpcRespondHandler) {
@@ -44,11 +46,11 @@ func (r *Rpc) Run() {
 		}
 	}
 	// 订阅本节点topic
-	topic := r.genNodeTopic(api.GetNode().GetID())
+	topic := r.genNodeTopic(r.node.GetID())
 	r.msgque.Subscribe(topic, process)
 
 	// 订阅广播组
-	for _, tag := range api.GetNode().GetTags() {
+	for _, tag := range r.node.GetTags() {
 		topic = r.genBroadcastTopic(tag)
 		r.msgque.Subscribe(topic, process)
 	}
@@ -74,10 +76,11 @@ func (r *Rpc) PostMessage(to *api.Pid, message *api.Message) *api.Error {
 }
 
 func (r *Rpc) send(topic string, message *api.Message) *api.Error {
-	if api.GetNode() == nil {
+	if r.node == nil {
 		return nil
 	}
-	buf, err := api.GetNode().Serializer().Marshal(message)
+	message.EncodeDeadline()
+	buf, err := r.node.Serializer().Marshal(message)
 	if err != nil {
 		zlog.Error("rpc marshal request err", zap.Error(err))
 		return api.ErrJsonPack
@@ -86,11 +89,15 @@ func (r *Rpc) send(topic string, message *api.Message) *api.Error {
 }
 
 func (r *Rpc) Call(to *api.Pid, timeout time.Duration, message *api.Message) (rsp *api.RespondMessage) {
-	if api.GetNode() == nil {
+	if r.node == nil {
 		return
 	}
 	rsp = new(api.RespondMessage)
-	data, err := api.GetNode().Serializer().Marshal(message)
+	if message.Deadline().IsZero() {
+		message.SetDeadline(time.Now().Add(timeout))
+	}
+	message.EncodeDeadline()
+	data, err := r.node.Serializer().Marshal(message)
 	if err != nil {
 		zlog.Error("rpc marshal request err", zap.Error(err))
 		rsp.Err = api.ErrJsonPack
@@ -100,7 +107,10 @@ func (r *Rpc) Call(to *api.Pid, timeout time.Duration, message *api.Message) (rs
 	if err != nil {
 		zlog.Error("rpc call  err", zap.Error(err))
 		rsp.Err = api.ErrNatsSend
-		return nil
+		if e, ok := err.(*api.Error); ok {
+			rsp.Err = e
+		}
+		return
 	}
 
 	if err = serializer.Json.Unmarshal(rspData, rsp); err != nil {
@@ -108,18 +118,26 @@ func (r *Rpc) Call(to *api.Pid, timeout time.Duration, message *api.Message) (rs
 		rsp.Err = api.ErrJsonUnPack
 		return
 	}
+	rsp.Err = api.FindErr(rsp.Err)
 	return
 }
 
 func (r *Rpc) process(data []byte, respond api.RpcRespondHandler) *api.Error {
-	if api.GetNode() == nil {
+	if r.node == nil {
 		return nil
 	}
 	message := new(api.Message)
-	if err := api.GetNode().Serializer().Unmarshal(data, message); err != nil {
+	if err := r.node.Serializer().Unmarshal(data, message); err != nil {
 		zlog.Error("rpc process  err", zap.Error(err))
 		return api.ErrJsonUnPack
 	}
+	message.DecodeDeadline()
+	// 调用方已超时,丢弃过期请求
+	if message.Expired() {
+		zlog.Warn("rpc process expired message", zap.String("method", message.Method),
+			zap.Uint64("from", message.From.GetNodeId()))
+		return nil
+	}
 	if respond != nil {
 		message.SetRespond(func(rsp *api.RespondMessage) *api.Error {
 			rspData, err := serializer.Json.Marshal(rsp)
@@ -129,7 +147,7 @@ func (r *Rpc) process(data []byte, respond api.RpcRespondHandler) *api.Error {
 			return respond(rspData)
 		})
 	}
-	return api.GetNode().System().PostMessage(message.To, message)
+	return r.node.System().PostMessage(message.To, message)
 }
 
 func (r *Rpc) genNodeTopic(nodeId uint64) string {
@@ -0,0 +1,97 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: manager
+ * @Version: 1.0.0
+ * @Date: 2025/1/24 10:40
+ */
+
+package singleton
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+)
+
+type LocateRequest struct{}
+
+// Manager
+// @Description: 每个承载节点一个,本节点被选中时启动单例,不再被选中时停止单例交给新节点
+type Manager struct {
+	api.BuiltinActor
+	settings *Settings
+	instance *api.Pid
+}
+
+func (m *Manager) OnInit(ctx api.IActorContext) *api.Error {
+	_ = m.BuiltinActor.OnInit(ctx)
+	ctx.AddGroup(api.ClusterUpdateGroup)
+	m.check()
+	return nil
+}
+
+// OnUpdateClusterGroup
+// @Description: 节点变化重新选举
+func (m *Manager) OnUpdateClusterGroup(_ []byte) *api.Error {
+	m.check()
+	return nil
+}
+
+// OnTerminated
+// @Description: 单例停止后仍被选中则稍后重新启动
+func (m *Manager) OnTerminated(terminated *api.Terminated) *api.Error {
+	if m.instance == nil || terminated.Who.GetUniqId() != m.instance.GetUniqId() {
+		return nil
+	}
+	m.instance = nil
+	m.Ctx.AfterFunc(retryInterval, nil, func(_ uint64, _ interface{}) {
+		m.check()
+	})
+	return nil
+}
+
+// Locate
+// @Description: 代理查询单例地址
+func (m *Manager) Locate(_ *LocateRequest) (*api.Pid, *api.Error) {
+	if m.instance == nil {
+		return nil, api.ErrSingletonNotRunning
+	}
+	return m.instance, nil
+}
+
+func (m *Manager) check() {
+	leader, ok := electLeader(m.Ctx.System().Node(), m.settings.Role)
+	if ok && leader == m.Ctx.Self().GetNodeId() {
+		m.spawn()
+	} else {
+		m.handOver()
+	}
+}
+
+func (m *Manager) spawn() {
+	if m.instance != nil {
+		return
+	}
+	opts := append([]api.ProcessOption{}, m.settings.Options...)
+	opts = append(opts, api.WithActorName(instanceName(m.settings.Name)))
+	pid, err := m.Ctx.Spawn(m.settings.Producer, nil, opts...)
+	if err != nil {
+		zlog.Error("singleton spawn", zap.String("name", m.settings.Name), zap.Error(err))
+		return
+	}
+	_ = m.Ctx.Watch(pid)
+	m.instance = pid
+	zlog.Info("singleton start", zap.String("name", m.settings.Name), zap.Uint64("uniqId", pid.GetUniqId()))
+}
+
+func (m *Manager) handOver() {
+	if m.instance == nil {
+		return
+	}
+	instance := m.instance
+	m.instance = nil
+	_ = m.Ctx.Unwatch(instance)
+	_ = m.Ctx.System().Kill(instance)
+	zlog.Info("singleton hand over", zap.String("name", m.settings.Name), zap.Uint64("uniqId", instance.GetUniqId()))
+}
@@ -0,0 +1,255 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: proxy
+ * @Version: 1.0.0
+ * @Date: 2025/1/24 11:05
+ */
+
+package singleton
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/reflectx"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+)
+
+// proxy
+// @Description: 每个节点一个,把消息转发给单例,单例切换期间缓存消息.
+// 使用forwardRouter,所有消息都交给Forward;集群变化和单例停止由子actor Monitor通知
+type proxy struct {
+	api.BuiltinActor
+	settings   *Settings
+	monitor    *Monitor
+	monitorPid *api.Pid
+	target     *api.Pid
+	buffered   int
+	// generation 每次重新定位递增,丢弃过期的定位结果
+	generation uint64
+}
+
+var _ api.IForwarder = &proxy{}
+
+// forwardRouter
+// @Description: 代理的路由,没有任何方法,发给代理的消息全部转发给单例,与代理的方法和类型是否导出无关
+type forwardRouter struct{}
+
+func (forwardRouter) Get(string) *reflectx.Method {
+	return nil
+}
+
+func (forwardRouter) Set(string, *reflectx.Method) {}
+
+// registerProxyRouter
+// @Description: 代理创建前注册路由,避免按反射生成
+// @param system
+func registerProxyRouter(system api.IActorSystem) {
+	system.SetRouter(reflectx.TypeFullName((*proxy)(nil)), forwardRouter{})
+}
+
+func (p *proxy) OnInit(ctx api.IActorContext) *api.Error {
+	_ = p.BuiltinActor.OnInit(ctx)
+	p.monitor = &Monitor{proxy: p, proxyPid: ctx.Self()}
+	pid, err := ctx.Spawn(func() api.IActor { return p.monitor }, nil)
+	if err != nil {
+		return err
+	}
+	p.monitorPid = pid
+	p.relocate()
+	return nil
+}
+
+// Forward
+// @Description: 找到单例直接转发,否则缓存等待定位完成.单例已停止或节点不存在时重新定位并重发
+func (p *proxy) Forward(ctx api.IActorContext, msg *api.Message) *api.Error {
+	if p.target == nil {
+		return p.buffer(ctx)
+	}
+	target := p.target
+	respond := msg.Responder()
+	if respond != nil {
+		msg.SetRespond(p.redeliver(msg, target, respond))
+	}
+	err := ctx.System().Forward(target, msg)
+	if !redeliverable(err) {
+		return err
+	}
+	msg.SetRespond(respond)
+	msg.To = ctx.Self()
+	p.invalidate(target)
+	return p.buffer(ctx)
+}
+
+// redeliver
+// @Description: 转发的调用回复单例不可达时,消息回到代理重新转发
+// @receiver p
+// @param msg
+// @param target 转发的目标
+// @param respond 调用方的回复函数
+// @return api.RespondFun
+func (p *proxy) redeliver(msg *api.Message, target *api.Pid, respond api.RespondFun) api.RespondFun {
+	system, self := p.Ctx.System(), p.Ctx.Self()
+	return func(rsp *api.RespondMessage) *api.Error {
+		if rsp == nil || !redeliverable(rsp.Err) || msg.Expired() {
+			return respond(rsp)
+		}
+		msg.SetRespond(respond)
+		msg.To = self
+		posted := continueWith(system, self, func() {
+			p.invalidate(target)
+			if process := system.Find(self); process != nil {
+				_ = process.PostMessage(msg)
+			}
+		})
+		if !posted {
+			return respond(rsp)
+		}
+		return nil
+	}
+}
+
+func (p *proxy) buffer(ctx api.IActorContext) *api.Error {
+	if p.buffered >= p.settings.BufferSize {
+		return api.ErrSingletonBufferFull
+	}
+	p.buffered++
+	return ctx.Stash()
+}
+
+// invalidate
+// @Description: 单例不可达,仍指向该单例时重新定位
+// @receiver p
+// @param target
+func (p *proxy) invalidate(target *api.Pid) {
+	if p.target != nil && p.target.Key() == target.Key() {
+		p.relocate()
+	}
+}
+
+// onUpdateCluster
+// @Description: 单例所在节点不再被选中时重新定位
+// @receiver p
+func (p *proxy) onUpdateCluster() {
+	leader, ok := electLeader(p.Ctx.System().Node(), p.settings.Role)
+	if p.target != nil && ok && leader == p.target.GetNodeId() {
+		return
+	}
+	p.relocate()
+}
+
+func (p *proxy) relocate() {
+	if p.target != nil {
+		target := p.target
+		p.watch(func(m *Monitor) { _ = m.Ctx.Unwatch(target) })
+		p.target = nil
+	}
+	p.generation++
+	p.locate(p.generation)
+}
+
+func (p *proxy) locate(generation uint64) {
+	if generation != p.generation {
+		return
+	}
+	leader, ok := electLeader(p.Ctx.System().Node(), p.settings.Role)
+	if !ok {
+		p.retry(generation)
+		return
+	}
+	manager := &api.Pid{NodeId: leader, Name: managerName(p.settings.Name)}
+	reply := new(api.Pid)
+	err := p.Ctx.CallAsync(manager, "Locate", &LocateRequest{}, reply, func(_ interface{}, err *api.Error) {
+		if generation != p.generation {
+			return
+		}
+		if err != nil {
+			p.retry(generation)
+			return
+		}
+		p.located(reply)
+	})
+	if err != nil {
+		p.retry(generation)
+	}
+}
+
+func (p *proxy) retry(generation uint64) {
+	p.Ctx.AfterFunc(retryInterval, nil, func(_ uint64, _ interface{}) {
+		p.locate(generation)
+	})
+}
+
+func (p *proxy) located(target *api.Pid) {
+	p.target = target
+	p.watch(func(m *Monitor) { _ = m.Ctx.Watch(target) })
+	zlog.Debug("singleton proxy located", zap.String("name", p.settings.Name),
+		zap.Uint64("nodeId", target.GetNodeId()), zap.Int("buffered", p.buffered))
+	p.buffered = 0
+	p.Ctx.UnstashAll()
+}
+
+// watch
+// @Description: 在Monitor的协程中修改监视
+// @receiver p
+// @param fn
+func (p *proxy) watch(fn func(m *Monitor)) {
+	if p.monitorPid == nil {
+		return
+	}
+	monitor := p.monitor
+	continueWith(p.Ctx.System(), p.monitorPid, func() { fn(monitor) })
+}
+
+// Monitor
+// @Description: 代理的子actor,订阅集群变化并监视单例,事件投递回代理的协程处理
+type Monitor struct {
+	api.BuiltinActor
+	proxy    *proxy
+	proxyPid *api.Pid
+}
+
+func (m *Monitor) OnInit(ctx api.IActorContext) *api.Error {
+	_ = m.BuiltinActor.OnInit(ctx)
+	ctx.AddGroup(api.ClusterUpdateGroup)
+	return nil
+}
+
+// OnUpdateClusterGroup
+// @Description: 节点变化,通知代理检查单例所在节点
+func (m *Monitor) OnUpdateClusterGroup(_ []byte) *api.Error {
+	continueWith(m.Ctx.System(), m.proxyPid, m.proxy.onUpdateCluster)
+	return nil
+}
+
+// OnTerminated
+// @Description: 单例停止,通知代理重新定位
+func (m *Monitor) OnTerminated(terminated *api.Terminated) *api.Error {
+	who := terminated.Who
+	continueWith(m.Ctx.System(), m.proxyPid, func() { m.proxy.invalidate(who) })
+	return nil
+}
+
+// redeliverable
+// @Description: 单例已停止或所在节点不存在,重新定位后可以重发
+// @param err
+// @return bool
+func redeliverable(err *api.Error) bool {
+	return err == api.ErrActorStopped || err == api.ErrProcessNotExist || err == api.ErrRpcNodeNotExist
+}
+
+// continueWith
+// @Description: 在目标actor的协程中执行fn
+// @param system
+// @param pid 本地actor
+// @param fn
+// @return bool 目标不存在返回false
+func continueWith(system api.IActorSystem, pid *api.Pid, fn func()) bool {
+	process := system.Find(pid)
+	if process == nil {
+		return false
+	}
+	message := &api.Message{Method: api.ContinuationFuncName, To: pid}
+	message.SetBody(fn)
+	return process.PostMessage(message) == nil
+}
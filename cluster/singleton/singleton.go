@@ -0,0 +1,130 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: singleton
+ * @Version: 1.0.0
+ * @Date: 2025/1/24 10:15
+ */
+
+package singleton
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"golang.org/x/exp/slices"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	namePrefix        = "singleton/"
+	defaultBufferSize = 10000
+	retryInterval     = 500 * time.Millisecond
+)
+
+// Settings
+// @Description: 集群单例配置,所有承载节点和代理节点使用相同的Name和Role
+type Settings struct {
+	Name string
+	// Producer 为nil时本节点只启动代理
+	Producer api.ActorProducer
+	// Role 承载单例的节点tag,为空时所有节点都可以承载
+	Role    string
+	Options []api.ProcessOption
+	// BufferSize 代理切换期间最多缓存的消息数量
+	BufferSize int
+}
+
+// Start
+// @Description: 启动单例管理器和代理,返回代理地址,发给代理的消息转发到集群中唯一的单例actor
+// @param node
+// @param settings
+// @return *api.Pid
+// @return *api.Error
+func Start(node api.INode, settings *Settings) (*api.Pid, *api.Error) {
+	system := node.System()
+	if settings.BufferSize <= 0 {
+		settings.BufferSize = defaultBufferSize
+	}
+	if settings.Producer != nil {
+		producer := func() api.IActor { return &Manager{settings: settings} }
+		if _, err := system.Spawn(producer, nil, api.WithActorName(managerName(settings.Name))); err != nil {
+			return nil, err
+		}
+	}
+	registerProxyRouter(system)
+	producer := func() api.IActor { return &proxy{settings: settings} }
+	return system.Spawn(producer, nil, api.WithActorName(proxyName(settings.Name)))
+}
+
+// ProxyPid
+// @Description: 本节点的代理地址
+// @param node
+// @param name
+// @return *api.Pid
+func ProxyPid(node api.INode, name string) *api.Pid {
+	return &api.Pid{NodeId: node.GetID(), Name: proxyName(name)}
+}
+
+func instanceName(name string) string {
+	return namePrefix + name
+}
+
+func managerName(name string) string {
+	return namePrefix + name + "/manager"
+}
+
+func proxyName(name string) string {
+	return namePrefix + name + "/proxy"
+}
+
+// electLeader
+// @Description: 承载节点中启动最早的节点,启动时间相同选id最小的
+// @param node
+// @param role
+// @return uint64
+// @return bool 没有可用节点返回false
+func electLeader(node api.INode, role string) (uint64, bool) {
+	discovery := node.Discovery()
+	if discovery == nil {
+		return node.GetID(), true
+	}
+	var nodes []api.INodeBase
+	if role == "" {
+		nodes = discovery.GetAll()
+	} else {
+		nodes = discovery.GetByKind(role)
+	}
+	if len(nodes) == 0 {
+		return 0, false
+	}
+	slices.SortFunc(nodes, func(a, b api.INodeBase) int {
+		ta, tb := startTime(a), startTime(b)
+		if ta != tb {
+			if ta < tb {
+				return -1
+			}
+			return 1
+		}
+		if a.GetID() < b.GetID() {
+			return -1
+		}
+		if a.GetID() > b.GetID() {
+			return 1
+		}
+		return 0
+	})
+	return nodes[0].GetID(), true
+}
+
+func startTime(node api.INodeBase) int64 {
+	v, ok := node.GetMeta()[api.MetaStartTime]
+	if !ok {
+		return math.MaxInt64
+	}
+	t, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return math.MaxInt64
+	}
+	return t
+}
@@ -0,0 +1,87 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: singleton_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/24 15:20
+ */
+
+package singleton
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/internal/testnode"
+)
+
+// Counter
+// @Description: 测试用单例,方法名与代理内部处理同名
+type Counter struct {
+	api.BuiltinActor
+	count int
+}
+
+func (c *Counter) Forward(delta *int) (*int, *api.Error) {
+	c.count += *delta
+	return &c.count, nil
+}
+
+func (c *Counter) OnUpdateClusterGroup(delta *int) (*int, *api.Error) {
+	return c.Forward(delta)
+}
+
+// newNode
+// @Description: 单例定位和切换较慢,调用超时改为3秒
+func newNode(t *testing.T, cluster string, id uint64) api.INode {
+	n := testnode.New(t, cluster, id)
+	n.System().SetTimeout(3 * time.Second)
+	return n
+}
+
+func add(t *testing.T, n api.INode, method string) int {
+	delta, count := 1, 0
+	if err := n.System().Call(nil, ProxyPid(n, "counter"), method, &delta, &count); err != nil {
+		t.Fatalf("%s = %v", method, err)
+	}
+	return count
+}
+
+func TestProxy(t *testing.T) {
+	n := newNode(t, "singleton-proxy", 1)
+	settings := &Settings{Name: "counter", Producer: func() api.IActor { return new(Counter) }}
+	if _, err := Start(n, settings); err != nil {
+		t.Fatal(err)
+	}
+	// 代理内部处理不在路由中,同名消息转发给单例
+	if got := add(t, n, "Forward"); got != 1 {
+		t.Fatalf("forward = %d", got)
+	}
+	if got := add(t, n, "OnUpdateClusterGroup"); got != 2 {
+		t.Fatalf("cluster update = %d", got)
+	}
+	process := n.System().Find(ProxyPid(n, "counter"))
+	if _, ok := process.Context().BaseRouter().(forwardRouter); !ok {
+		t.Fatal("proxy not using the forward router")
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	n := newNode(t, "singleton-redeliver", 1)
+	settings := &Settings{Name: "counter", Producer: func() api.IActor { return new(Counter) }}
+	if _, err := Start(n, settings); err != nil {
+		t.Fatal(err)
+	}
+	add(t, n, "Forward")
+	instance := &api.Pid{NodeId: n.GetID(), Name: instanceName("counter")}
+	process := n.System().Find(instance)
+	if process == nil {
+		t.Fatal("singleton not running")
+	}
+	// 单例停止后代理重新定位并重发,调用方不会收到actor已停止
+	_ = n.System().Kill(process.Pid())
+	if got := add(t, n, "Forward"); got != 1 {
+		t.Fatalf("after restart = %d", got)
+	}
+}
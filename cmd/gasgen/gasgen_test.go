@@ -0,0 +1,64 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: gasgen_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/17 15:20
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新golden文件")
+
+// checkGenerate
+// @Description: 生成结果与期望文件逐字节比较
+func checkGenerate(t *testing.T, dir, typ, output, expect string) {
+	t.Helper()
+	pkg, err := parsePackage(dir, []string{typ}, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(expect, src, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(expect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Fatalf("generated code differs from %s, run go test -update to accept:\n%s", expect, src)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("testdata", "room")
+	checkGenerate(t, dir, "Room", "room_gas.go", filepath.Join(dir, "room_gas.go.golden"))
+}
+
+// TestGenerateExample
+// @Description: 示例中提交的生成代码与生成器一致
+func TestGenerateExample(t *testing.T) {
+	dir := filepath.Join("..", "..", "examples", "nodes", "chat")
+	checkGenerate(t, dir, "Service", "service_gas.go", filepath.Join(dir, "service_gas.go"))
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := parsePackage(filepath.Join("testdata", "room"), []string{"Missing"}, "room_gas.go"); err == nil {
+		t.Fatal("type without handler accepted")
+	}
+}
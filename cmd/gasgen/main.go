@@ -0,0 +1,74 @@
+/**
+ * @Author: dingQingHui
+ * @Description: actor代码生成工具,生成switch分发函数和调用代理,替换反射调用
+ * @File: main
+ * @Version: 1.0.0
+ * @Date: 2025/1/17 10:00
+ */
+
+// gasgen 在actor所在包中使用:
+//
+//	//go:generate go run github.com/dingqinghui/gas/cmd/gasgen -type Service
+//
+// 生成 RegisterServiceRouter(system) 和 NewServiceClient(system, pid)
+package main
+
+import (
+	"fmt"
+	"github.com/urfave/cli"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "gasgen"
+	app.Usage = "generate typed actor dispatcher and client"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "type,t",
+			Usage: "actor类型,多个用逗号分隔",
+		},
+		cli.StringFlag{
+			Name:  "output,o",
+			Usage: "输出文件,默认为<第一个类型>_gas.go",
+		},
+	}
+	app.Action = run
+	if err := app.Run(os.Args); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "gasgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args *cli.Context) error {
+	if args.String("type") == "" {
+		return fmt.Errorf("missing -type")
+	}
+	dir := "."
+	if args.NArg() > 0 {
+		dir = args.Args().First()
+	}
+	var types []string
+	for _, name := range strings.Split(args.String("type"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			types = append(types, name)
+		}
+	}
+	output := args.String("output")
+	if output == "" {
+		output = strings.ToLower(types[0]) + "_gas.go"
+	}
+	output = filepath.Join(dir, output)
+
+	pkg, err := parsePackage(dir, types, filepath.Base(output))
+	if err != nil {
+		return err
+	}
+	src, err := generate(pkg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0644)
+}
@@ -0,0 +1,337 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: parse
+ * @Version: 1.0.0
+ * @Date: 2025/1/17 10:20
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const apiPath = "github.com/dingqinghui/gas/api"
+
+type methodKind int
+
+const (
+	innerMethod methodKind = iota
+	networkMethod
+)
+
+type method struct {
+	Name       string
+	Kind       methodKind
+	Request    string // 参数类型,空表示没有参数
+	RequestNew string // 指针参数的元素类型
+	Reply      string // 返回值类型,空表示只返回错误
+	ReplyNew   string
+	ReplyNil   bool // 返回值可以为nil
+	NoResult   bool // 没有返回值
+	Callback   bool // 框架回调,不生成调用代理
+}
+
+// ArgNum 与反射路由一致,包含接收者
+func (m *method) ArgNum() int {
+	if m.Kind == networkMethod {
+		return 3
+	}
+	if m.Request == "" {
+		return 1
+	}
+	return 2
+}
+
+type actorType struct {
+	Name    string
+	Methods []*method
+}
+
+type pkgInfo struct {
+	Name    string
+	Types   []*actorType
+	Imports []string
+}
+
+// HasClient 存在可以通过Call调用的方法
+func (p *pkgInfo) HasClient() bool {
+	for _, t := range p.Types {
+		if t.HasClient() {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *actorType) HasClient() bool {
+	for _, m := range t.Methods {
+		if m.Kind == innerMethod && !m.Callback {
+			return true
+		}
+	}
+	return false
+}
+
+var skipMethods = map[string]bool{
+	"OnInit": true,
+	"OnStop": true,
+}
+
+// callbackMethods 由框架投递的回调
+var callbackMethods = map[string]bool{
+	"OnTerminated":     true,
+	"OnReceiveTimeout": true,
+	"OnDeadLetter":     true,
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+func parsePackage(dir string, types []string, output string) (*pkgInfo, error) {
+	fset := token.NewFileSet()
+	filter := func(info fs.FileInfo) bool {
+		name := info.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != output
+	}
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+	if pkg == nil || len(pkgs) != 1 {
+		return nil, fmt.Errorf("expect one package in %s", dir)
+	}
+
+	info := &pkgInfo{Name: pkg.Name}
+	wanted := make(map[string]*actorType)
+	for _, name := range types {
+		t := &actorType{Name: name}
+		wanted[name] = t
+		info.Types = append(info.Types, t)
+	}
+	imports := map[string]string{apiPath: "api"}
+
+	fileNames := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		file := pkg.Files[fileName]
+		fileImports := importNames(file)
+		apiName := ""
+		for name, importPath := range fileImports {
+			if importPath == apiPath {
+				apiName = name
+			}
+		}
+		for _, decl := range file.Decls {
+			fun, ok := decl.(*ast.FuncDecl)
+			if !ok || fun.Recv == nil || len(fun.Recv.List) != 1 {
+				continue
+			}
+			t, ok := wanted[receiverName(fun.Recv.List[0].Type)]
+			if !ok || !fun.Name.IsExported() || skipMethods[fun.Name.Name] {
+				continue
+			}
+			m, reason := parseMethod(fset, fun, apiName)
+			if m == nil {
+				fmt.Printf("gasgen: skip %s.%s: %s\n", t.Name, fun.Name.Name, reason)
+				continue
+			}
+			if apiName != "" && apiName != "api" {
+				return nil, fmt.Errorf("%s: api package must be imported as api", fileName)
+			}
+			for _, name := range usedPackages(fun.Type) {
+				importPath, ok := fileImports[name]
+				if !ok {
+					continue
+				}
+				if exist, ok := imports[importPath]; ok && exist != name {
+					return nil, fmt.Errorf("import %s used with different names", importPath)
+				}
+				imports[importPath] = name
+			}
+			t.Methods = append(t.Methods, m)
+		}
+	}
+	for _, t := range info.Types {
+		if len(t.Methods) == 0 {
+			return nil, fmt.Errorf("type %s has no handler method", t.Name)
+		}
+		sort.Slice(t.Methods, func(i, j int) bool { return t.Methods[i].Name < t.Methods[j].Name })
+	}
+	for importPath, name := range imports {
+		if importPath == apiPath {
+			continue
+		}
+		if defaultName(importPath) == name {
+			info.Imports = append(info.Imports, strconv.Quote(importPath))
+		} else {
+			info.Imports = append(info.Imports, name+" "+strconv.Quote(importPath))
+		}
+	}
+	sort.Strings(info.Imports)
+	return info, nil
+}
+
+// parseMethod
+// @Description: 支持 F() F(req) F(session, req),返回 无 / error / (reply, error)
+func parseMethod(fset *token.FileSet, fun *ast.FuncDecl, apiName string) (*method, string) {
+	m := &method{Name: fun.Name.Name, Kind: innerMethod, Callback: callbackMethods[fun.Name.Name]}
+	params := expandFields(fun.Type.Params)
+	switch len(params) {
+	case 0:
+	case 1:
+		if isSelector(params[0], apiName, "IActorContext") {
+			return nil, "context argument"
+		}
+		m.Request, m.RequestNew = typeString(fset, params[0])
+	case 2:
+		if star, ok := params[0].(*ast.StarExpr); !ok || !isSelector(star.X, apiName, "Session") {
+			return nil, "first argument of network method must be *api.Session"
+		}
+		m.Kind = networkMethod
+		m.Request, m.RequestNew = typeString(fset, params[1])
+	default:
+		return nil, "too many arguments"
+	}
+
+	results := expandFields(fun.Type.Results)
+	switch len(results) {
+	case 0:
+		m.NoResult = true
+	case 1:
+		if !isError(results[0], apiName) {
+			return nil, "result must be *api.Error"
+		}
+	case 2:
+		if !isError(results[1], apiName) {
+			return nil, "second result must be *api.Error"
+		}
+		if m.Kind == networkMethod {
+			return nil, "network method can not reply"
+		}
+		m.Reply, m.ReplyNew = typeString(fset, results[0])
+		m.ReplyNil = nilable(results[0])
+	default:
+		return nil, "too many results"
+	}
+	return m, ""
+}
+
+func expandFields(fields *ast.FieldList) []ast.Expr {
+	if fields == nil {
+		return nil
+	}
+	var result []ast.Expr
+	for _, field := range fields.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			result = append(result, field.Type)
+		}
+	}
+	return result
+}
+
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkg && sel.Sel.Name == name
+}
+
+func isError(expr ast.Expr, apiName string) bool {
+	star, ok := expr.(*ast.StarExpr)
+	return ok && isSelector(star.X, apiName, "Error")
+}
+
+func nilable(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.InterfaceType, *ast.ChanType, *ast.FuncType:
+		return true
+	case *ast.ArrayType:
+		return t.Len == nil
+	}
+	return false
+}
+
+// typeString
+// @Description: 类型源码,指针类型同时返回元素类型
+func typeString(fset *token.FileSet, expr ast.Expr) (string, string) {
+	var elem string
+	if star, ok := expr.(*ast.StarExpr); ok {
+		elem = exprString(fset, star.X)
+	}
+	return exprString(fset, expr), elem
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+func importNames(file *ast.File) map[string]string {
+	result := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := defaultName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		result[name] = importPath
+	}
+	return result
+}
+
+func defaultName(importPath string) string {
+	name := path.Base(importPath)
+	if versionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	return name
+}
+
+func usedPackages(expr ast.Expr) []string {
+	var names []string
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				names = append(names, ident.Name)
+			}
+		}
+		return true
+	})
+	return names
+}
@@ -0,0 +1,158 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: template
+ * @Version: 1.0.0
+ * @Date: 2025/1/17 11:00
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+var fileTemplate = template.Must(template.New("gas").Parse(`// Code generated by gasgen. DO NOT EDIT.
+
+package {{.Name}}
+
+import (
+{{- if .HasClient}}
+	"context"
+{{- end}}
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/reflectx"
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+{{range $t := .Types}}
+// Register{{$t.Name}}Router
+// @Description: 注册{{$t.Name}}的分发函数,替换反射调用,在Spawn之前调用
+// @param system
+func Register{{$t.Name}}Router(system api.IActorSystem) {
+	router := system.GetOrSetRouter((*{{$t.Name}})(nil))
+	serializer := system.Node().Serializer()
+	invoke := func(receiver interface{}, name string, session interface{}, data []byte) (interface{}, error) {
+		return dispatch{{$t.Name}}(serializer, receiver, name, session, data)
+	}
+{{- range $t.Methods}}
+	router.Set("{{.Name}}", &reflectx.Method{Name: "{{.Name}}", ArgNum: {{.ArgNum}}, Invoke: invoke})
+{{- end}}
+}
+
+func dispatch{{$t.Name}}(serializer api.ISerializer, receiver interface{}, name string, session interface{}, data []byte) (interface{}, error) {
+	actor := receiver.(*{{$t.Name}})
+	switch name {
+{{- range $t.Methods}}
+	case "{{.Name}}":
+{{- if .Request}}
+{{- if eq .Request "[]byte"}}
+		request := data
+{{- else if .RequestNew}}
+		request := new({{.RequestNew}})
+		if err := api.DecodeArg(serializer, data, request); err != nil {
+			return nil, err
+		}
+{{- else}}
+		var request {{.Request}}
+		if err := api.DecodeArg(serializer, data, &request); err != nil {
+			return nil, err
+		}
+{{- end}}
+{{- end}}
+{{- if eq .Kind 1}}
+		s, _ := session.(*api.Session)
+{{- end}}
+{{- if .NoResult}}
+		actor.{{.Name}}({{if eq .Kind 1}}s, {{end}}{{if .Request}}request{{end}})
+		return nil, nil
+{{- else if .Reply}}
+		reply, err := actor.{{.Name}}({{if .Request}}request{{end}})
+		var result interface{}
+{{- if .ReplyNil}}
+		if reply != nil {
+			result = reply
+		}
+{{- else}}
+		result = reply
+{{- end}}
+		if err != nil {
+			return result, err
+		}
+		return result, nil
+{{- else}}
+		if err := actor.{{.Name}}({{if eq .Kind 1}}s, {{end}}{{if .Request}}request{{end}}); err != nil {
+			return nil, err
+		}
+		return nil, nil
+{{- end}}
+{{- end}}
+	}
+	return nil, api.ErrActorNotMethod
+}
+{{- if $t.HasClient}}
+
+// {{$t.Name}}Client
+// @Description: {{$t.Name}}的调用代理
+type {{$t.Name}}Client struct {
+	system api.IActorSystem
+	from   *api.Pid
+	to     *api.Pid
+}
+
+// New{{$t.Name}}Client
+// @Description: 通过system发送请求,在actor中调用时传入ctx.System()
+func New{{$t.Name}}Client(system api.IActorSystem, to *api.Pid) *{{$t.Name}}Client {
+	return &{{$t.Name}}Client{system: system, to: to}
+}
+
+// WithFrom
+// @Description: 设置发送者,在actor中调用时传入ctx.Self()
+func (c *{{$t.Name}}Client) WithFrom(from *api.Pid) *{{$t.Name}}Client {
+	return &{{$t.Name}}Client{system: c.system, from: from, to: c.to}
+}
+{{- range $t.Methods}}
+{{- if and (eq .Kind 0) (not .Callback)}}
+{{if .Reply}}
+func (c *{{$t.Name}}Client) {{.Name}}(ctx context.Context{{if .Request}}, request {{.Request}}{{end}}) ({{.Reply}}, *api.Error) {
+{{- if .ReplyNew}}
+	reply := new({{.ReplyNew}})
+	if err := c.system.CallContext(ctx, c.from, c.to, "{{.Name}}", {{if .Request}}request{{else}}struct{}{}{{end}}, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+{{- else}}
+	var reply {{.Reply}}
+	err := c.system.CallContext(ctx, c.from, c.to, "{{.Name}}", {{if .Request}}request{{else}}struct{}{}{{end}}, &reply)
+	return reply, err
+{{- end}}
+}
+{{- else}}
+func (c *{{$t.Name}}Client) {{.Name}}(ctx context.Context{{if .Request}}, request {{.Request}}{{end}}) *api.Error {
+	return c.system.CallContext(ctx, c.from, c.to, "{{.Name}}", {{if .Request}}request{{else}}struct{}{}{{end}}, nil)
+}
+
+func (c *{{$t.Name}}Client) Send{{.Name}}({{if .Request}}request {{.Request}}{{end}}) *api.Error {
+	return c.system.Send(c.from, c.to, "{{.Name}}", {{if .Request}}request{{else}}struct{}{}{{end}})
+}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{end}}`))
+
+func generate(pkg *pkgInfo) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, pkg); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, buf.String())
+	}
+	return src, nil
+}
@@ -0,0 +1,67 @@
+/**
+ * @Author: dingQingHui
+ * @Description: gasgen测试用actor,覆盖支持的方法签名
+ * @File: room
+ * @Version: 1.0.0
+ * @Date: 2025/1/17 15:00
+ */
+
+package room
+
+import (
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	pb "github.com/dingqinghui/gas/examples/common"
+)
+
+type Room struct {
+	api.BuiltinActor
+	members []string
+}
+
+func (r *Room) OnInit(ctx api.IActorContext) *api.Error {
+	return r.BuiltinActor.OnInit(ctx)
+}
+
+// Join 指针请求和回复
+func (r *Room) Join(request *pb.RpcRoomJoin) (*pb.RpcRoomJoin, *api.Error) {
+	return request, nil
+}
+
+// Count 没有参数,值类型回复
+func (r *Room) Count() (int, *api.Error) {
+	return len(r.members), nil
+}
+
+// Members 值类型参数,可以为nil的回复
+func (r *Room) Members(prefix []string) ([]string, *api.Error) {
+	return r.members, nil
+}
+
+// Close 只返回错误
+func (r *Room) Close() *api.Error {
+	return nil
+}
+
+// Tick 没有返回值
+func (r *Room) Tick(d time.Duration) {}
+
+// Chat 网络消息
+func (r *Room) Chat(session *api.Session, message *pb.ClientMessage) *api.Error {
+	return session.Response(message)
+}
+
+// OnTerminated 框架回调,不生成调用代理
+func (r *Room) OnTerminated(terminated *api.Terminated) *api.Error {
+	return nil
+}
+
+// Use 不支持的签名,跳过
+func (r *Room) Use(ctx api.IActorContext) *api.Error {
+	return nil
+}
+
+func (r *Room) reset() {
+	r.members = nil
+}
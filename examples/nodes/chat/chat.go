@@ -16,6 +16,8 @@ import (
 	"go.uber.org/zap"
 )
 
+//go:generate go run github.com/dingqinghui/gas/cmd/gasgen -type Service
+
 type Message struct {
 	Name    string
 	Content string
@@ -35,7 +37,19 @@ func (c *Service) OnInit(ctx api.IActorContext) *api.Error {
 // async handler
 func (c *Service) Join(message *common.RpcRoomJoin) *api.Error {
 	zlog.Info("ChatService Join", zap.Any("message", message), zap.Any("message", c.Ctx.Message().From))
-	c.dict[message.UserId] = c.Ctx.Message().From
+	from := c.Ctx.Message().From
+	c.dict[message.UserId] = from
+	return c.Ctx.Watch(from)
+}
+
+// watched actor stopped
+func (c *Service) OnTerminated(message *api.Terminated) *api.Error {
+	zlog.Info("ChatService OnTerminated", zap.Any("who", message.Who), zap.Int32("reason", int32(message.Reason)))
+	for userId, pid := range c.dict {
+		if pid.Equal(message.Who) {
+			delete(c.dict, userId)
+		}
+	}
 	return nil
 }
 
@@ -61,9 +75,10 @@ func (c *Service) Chat(session *api.Session, message *common.ClientMessage) *api
 }
 
 func RunChatNode(path string) {
-	node.New(path)
+	chatNode := node.New(path)
 
-	api.GetNode().Run()
-	_, _ = api.GetNode().System().Spawn(func() api.IActor { return new(Service) }, nil, api.WithActorName("chat"))
-	api.GetNode().Wait()
+	chatNode.Run()
+	RegisterServiceRouter(chatNode.System())
+	_, _ = chatNode.System().Spawn(func() api.IActor { return new(Service) }, nil, api.WithActorName("chat"))
+	chatNode.Wait()
 }
@@ -0,0 +1,110 @@
+// Code generated by gasgen. DO NOT EDIT.
+
+package chat
+
+import (
+	"context"
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/examples/common"
+	"github.com/dingqinghui/gas/extend/reflectx"
+)
+
+// RegisterServiceRouter
+// @Description: 注册Service的分发函数,替换反射调用,在Spawn之前调用
+// @param system
+func RegisterServiceRouter(system api.IActorSystem) {
+	router := system.GetOrSetRouter((*Service)(nil))
+	serializer := system.Node().Serializer()
+	invoke := func(receiver interface{}, name string, session interface{}, data []byte) (interface{}, error) {
+		return dispatchService(serializer, receiver, name, session, data)
+	}
+	router.Set("Chat", &reflectx.Method{Name: "Chat", ArgNum: 3, Invoke: invoke})
+	router.Set("Join", &reflectx.Method{Name: "Join", ArgNum: 2, Invoke: invoke})
+	router.Set("OnTerminated", &reflectx.Method{Name: "OnTerminated", ArgNum: 2, Invoke: invoke})
+	router.Set("SyncJoin1", &reflectx.Method{Name: "SyncJoin1", ArgNum: 2, Invoke: invoke})
+}
+
+func dispatchService(serializer api.ISerializer, receiver interface{}, name string, session interface{}, data []byte) (interface{}, error) {
+	actor := receiver.(*Service)
+	switch name {
+	case "Chat":
+		request := new(common.ClientMessage)
+		if err := api.DecodeArg(serializer, data, request); err != nil {
+			return nil, err
+		}
+		s, _ := session.(*api.Session)
+		if err := actor.Chat(s, request); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case "Join":
+		request := new(common.RpcRoomJoin)
+		if err := api.DecodeArg(serializer, data, request); err != nil {
+			return nil, err
+		}
+		if err := actor.Join(request); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case "OnTerminated":
+		request := new(api.Terminated)
+		if err := api.DecodeArg(serializer, data, request); err != nil {
+			return nil, err
+		}
+		if err := actor.OnTerminated(request); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case "SyncJoin1":
+		request := new(common.RpcRoomJoin)
+		if err := api.DecodeArg(serializer, data, request); err != nil {
+			return nil, err
+		}
+		reply, err := actor.SyncJoin1(request)
+		var result interface{}
+		if reply != nil {
+			result = reply
+		}
+		if err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+	return nil, api.ErrActorNotMethod
+}
+
+// ServiceClient
+// @Description: Service的调用代理
+type ServiceClient struct {
+	system api.IActorSystem
+	from   *api.Pid
+	to     *api.Pid
+}
+
+// NewServiceClient
+// @Description: 通过system发送请求,在actor中调用时传入ctx.System()
+func NewServiceClient(system api.IActorSystem, to *api.Pid) *ServiceClient {
+	return &ServiceClient{system: system, to: to}
+}
+
+// WithFrom
+// @Description: 设置发送者,在actor中调用时传入ctx.Self()
+func (c *ServiceClient) WithFrom(from *api.Pid) *ServiceClient {
+	return &ServiceClient{system: c.system, from: from, to: c.to}
+}
+
+func (c *ServiceClient) Join(ctx context.Context, request *common.RpcRoomJoin) *api.Error {
+	return c.system.CallContext(ctx, c.from, c.to, "Join", request, nil)
+}
+
+func (c *ServiceClient) SendJoin(request *common.RpcRoomJoin) *api.Error {
+	return c.system.Send(c.from, c.to, "Join", request)
+}
+
+func (c *ServiceClient) SyncJoin1(ctx context.Context, request *common.RpcRoomJoin) (*common.RpcRoomJoin, *api.Error) {
+	reply := new(common.RpcRoomJoin)
+	if err := c.system.CallContext(ctx, c.from, c.to, "SyncJoin1", request, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
@@ -29,7 +29,7 @@ type ServerAgent struct {
 func (a *ServerAgent) Login(session *api.Session, message *common.ClientMessage) *api.Error {
 	zlog.Info("agent receive message", zap.Any("message", message))
 
-	chatPid := cluster.NewPid("chat", balancer.NewRandom(), nil)
+	chatPid := cluster.NewPid(a.Ctx.System().Node(), "chat", balancer.NewLeastLoad(nil), nil)
 	if chatPid == nil {
 		return api.ErrPidIsNil
 	}
@@ -86,8 +86,8 @@ func NetRouterFunc(session *api.Session, msg *message.Message) (*api.Pid, string
 		return nil, "", api.ErrNetworkRoute
 	}
 	to := session.Agent
-	if !slices.Contains(api.GetNode().GetTags(), router.GetService()) {
-		to = cluster.NewPid("chat", balancer.NewRandom(), nil)
+	if !slices.Contains(session.Node().GetTags(), router.GetService()) {
+		to = cluster.NewPid(session.Node(), "chat", balancer.NewRandom(), nil)
 	}
 	return to, router.GetMethod(), nil
 }
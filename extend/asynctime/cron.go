@@ -0,0 +1,159 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: cron
+ * @Version: 1.0.0
+ * @Date: 2025/1/13 11:02
+ */
+
+package asynctime
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrCronSpec = errors.New("invalid cron spec")
+
+type cronField struct {
+	min, max uint
+}
+
+var (
+	secondField = cronField{0, 59}
+	minuteField = cronField{0, 59}
+	hourField   = cronField{0, 23}
+	domField    = cronField{1, 31}
+	monthField  = cronField{1, 12}
+	dowField    = cronField{0, 6}
+)
+
+// CronSchedule
+// @Description: cron表达式,支持 "分 时 日 月 周" 和 "秒 分 时 日 月 周",字段支持 * , - /
+type CronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+	location                              *time.Location
+}
+
+func ParseCron(spec string) (*CronSchedule, error) {
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, ErrCronSpec
+	}
+	s := &CronSchedule{location: time.Local}
+	var err error
+	if s.second, err = parseCronField(fields[0], secondField); err != nil {
+		return nil, err
+	}
+	if s.minute, err = parseCronField(fields[1], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[2], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[3], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[4], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[5], dowField); err != nil {
+		return nil, err
+	}
+	s.domStar = fields[3] == "*" || fields[3] == "?"
+	s.dowStar = fields[5] == "*" || fields[5] == "?"
+	return s, nil
+}
+
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := uint(1)
+		if i := strings.Index(part, "/"); i >= 0 {
+			v, err := strconv.ParseUint(part[i+1:], 10, 32)
+			if err != nil || v == 0 {
+				return 0, ErrCronSpec
+			}
+			step = uint(v)
+			part = part[:i]
+		}
+		start, end := f.min, f.max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			pair := strings.SplitN(part, "-", 2)
+			l, err1 := strconv.ParseUint(pair[0], 10, 32)
+			r, err2 := strconv.ParseUint(pair[1], 10, 32)
+			if err1 != nil || err2 != nil {
+				return 0, ErrCronSpec
+			}
+			start, end = uint(l), uint(r)
+		default:
+			v, err := strconv.ParseUint(part, 10, 32)
+			if err != nil {
+				return 0, ErrCronSpec
+			}
+			start, end = uint(v), uint(v)
+			if step > 1 {
+				end = f.max
+			}
+		}
+		if start < f.min || end > f.max || start > end {
+			return 0, ErrCronSpec
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+// Next
+// @Description: 实现timingwheel.Scheduler,返回prev之后的下一次执行时间(UTC)
+// @receiver s
+// @param prev
+// @return time.Time
+func (s *CronSchedule) Next(prev time.Time) time.Time {
+	t := prev.In(s.location).Add(time.Second).Truncate(time.Second)
+	yearLimit := t.Year() + 5
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if s.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t.UTC()
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,85 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: cron_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/13 15:20
+ */
+
+package asynctime
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, min, sec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, 0, time.Local)
+}
+
+func TestParseCron(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 0-6,18 1 * ?",
+		"30 0 9 * * 1-5",
+		"0 0 1 1 *",
+		"5/20 * * * *",
+	}
+	for _, spec := range valid {
+		if _, err := ParseCron(spec); err != nil {
+			t.Fatalf("%q = %v", spec, err)
+		}
+	}
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseCron(spec); err != ErrCronSpec {
+			t.Fatalf("%q = %v", spec, err)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	cases := []struct {
+		spec string
+		prev time.Time
+		next time.Time
+	}{
+		{"*/15 * * * *", date(2025, 1, 31, 10, 7, 0), date(2025, 1, 31, 10, 15, 0)},
+		{"30 0 9 * * *", date(2025, 1, 31, 9, 0, 30), date(2025, 2, 1, 9, 0, 30)},
+		// 跨月、跳过没有31号的月份
+		{"0 0 1 * *", date(2025, 1, 31, 10, 0, 0), date(2025, 2, 1, 0, 0, 0)},
+		{"0 12 31 * *", date(2025, 2, 1, 0, 0, 0), date(2025, 3, 31, 12, 0, 0)},
+		{"0 0 29 2 *", date(2025, 3, 1, 0, 0, 0), date(2028, 2, 29, 0, 0, 0)},
+		// 跨年
+		{"0 0 1 1 *", date(2025, 6, 1, 0, 0, 0), date(2026, 1, 1, 0, 0, 0)},
+		// 2025-01-31是周五,周一跨月
+		{"0 9 * * 1", date(2025, 1, 31, 10, 0, 0), date(2025, 2, 3, 9, 0, 0)},
+		{"30 0 9 * * 1-5", date(2025, 1, 31, 9, 0, 30), date(2025, 2, 3, 9, 0, 30)},
+		{"0 0 * * 0", date(2025, 2, 1, 12, 0, 0), date(2025, 2, 2, 0, 0, 0)},
+		// 日和周都指定时满足任意一个
+		{"0 0 13 * 5", date(2025, 2, 1, 0, 0, 0), date(2025, 2, 7, 0, 0, 0)},
+		{"0 0 13 * 5", date(2025, 2, 8, 0, 0, 0), date(2025, 2, 13, 0, 0, 0)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseCron(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next := schedule.Next(c.prev); !next.Equal(c.next) {
+			t.Fatalf("%q after %v = %v, want %v", c.spec, c.prev, next.In(time.Local), c.next)
+		}
+	}
+}
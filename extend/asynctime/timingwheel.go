@@ -22,3 +22,19 @@ func init() {
 func AfterFunc(d time.Duration, f func()) *timingwheel.Timer {
 	return tw.AfterFunc(d, f)
 }
+
+func Every(d time.Duration, f func()) *timingwheel.Timer {
+	return tw.ScheduleFunc(&everyScheduler{interval: d}, f)
+}
+
+func Schedule(s timingwheel.Scheduler, f func()) *timingwheel.Timer {
+	return tw.ScheduleFunc(s, f)
+}
+
+type everyScheduler struct {
+	interval time.Duration
+}
+
+func (s *everyScheduler) Next(prev time.Time) time.Time {
+	return prev.Add(s.interval)
+}
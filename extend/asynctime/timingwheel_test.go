@@ -0,0 +1,56 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: timingwheel_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/13 15:40
+ */
+
+package asynctime
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAfterFunc(t *testing.T) {
+	fired := make(chan time.Time, 1)
+	start := time.Now()
+	AfterFunc(30*time.Millisecond, func() { fired <- time.Now() })
+	select {
+	case at := <-fired:
+		if at.Sub(start) < 20*time.Millisecond {
+			t.Fatalf("fired after %v", at.Sub(start))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not fired")
+	}
+
+	var cancelled atomic.Bool
+	timer := AfterFunc(30*time.Millisecond, func() { cancelled.Store(true) })
+	timer.Stop()
+	time.Sleep(60 * time.Millisecond)
+	if cancelled.Load() {
+		t.Fatal("stopped timer fired")
+	}
+}
+
+func TestEvery(t *testing.T) {
+	var count atomic.Int32
+	timer := Every(10*time.Millisecond, func() { count.Add(1) })
+	deadline := time.Now().Add(time.Second)
+	for count.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("fired %d times", count.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	timer.Stop()
+	time.Sleep(20 * time.Millisecond)
+	stopped := count.Load()
+	time.Sleep(50 * time.Millisecond)
+	if count.Load() != stopped {
+		t.Fatal("stopped timer fired")
+	}
+}
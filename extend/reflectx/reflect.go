@@ -20,6 +20,8 @@ type Method struct {
 	Typ      reflect.Type
 	ArgTypes []reflect.Type
 	ArgNum   int
+	// Invoke 代码生成的分发函数,不为nil时不使用反射调用,session为网络消息的*api.Session
+	Invoke func(receiver interface{}, name string, session interface{}, data []byte) (reply interface{}, err error)
 }
 
 func TypeFullName(v interface{}) string {
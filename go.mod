@@ -8,6 +8,7 @@ require (
 	github.com/RussellLuo/timingwheel v0.0.0-20220218152713-54845bda3108
 	github.com/dingqinghui/extend v0.0.0-20241121074411-691277978e46
 	github.com/duke-git/lancet/v2 v2.3.3
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/hashicorp/consul/api v1.30.0
 	github.com/nats-io/nats.go v1.37.0
 	github.com/panjf2000/ants/v2 v2.10.0
@@ -25,7 +26,6 @@ require (
 	github.com/armon/go-metrics v0.4.1 // indirect
 	github.com/cpuguy83/go-md2man/v2 v2.0.5 // indirect
 	github.com/fatih/color v1.16.0 // indirect
-	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/hashicorp/go-hclog v1.5.0 // indirect
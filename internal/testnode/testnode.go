@@ -0,0 +1,93 @@
+/**
+ * @Author: dingQingHui
+ * @Description: 测试用节点,内存服务发现+loopback rpc,同一进程中按集群名互相发现
+ * @File: testnode
+ * @Version: 1.0.0
+ * @Date: 2025/2/6 10:20
+ */
+
+package testnode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/node"
+)
+
+type (
+	// Option
+	// @Description: 修改节点的node配置
+	Option func(config map[string]interface{})
+)
+
+// WithTags
+// @Description: 节点的tags,按kind查找节点时使用
+func WithTags(tags ...string) Option {
+	return func(config map[string]interface{}) {
+		config["tags"] = tags
+	}
+}
+
+// WithNode
+// @Description: 设置node配置中的其他字段,如loadReportInterval
+func WithNode(key string, value interface{}) Option {
+	return func(config map[string]interface{}) {
+		config[key] = value
+	}
+}
+
+// New
+// @Description: 创建并运行节点,测试结束时停止
+// @param t
+// @param cluster 集群名,不同测试使用不同的名字互不干扰
+// @param id 节点id
+// @param opts
+// @return api.INode
+func New(t testing.TB, cluster string, id uint64, opts ...Option) api.INode {
+	t.Helper()
+	dir := t.TempDir()
+	nodeConfig := map[string]interface{}{"id": id}
+	for _, opt := range opts {
+		opt(nodeConfig)
+	}
+	config := map[string]interface{}{
+		"cluster": map[string]interface{}{"name": cluster, "discovery": "memory", "rpc": "loopback"},
+		"log":     map[string]interface{}{"path": dir, "printConsole": false},
+		"node":    nodeConfig,
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "node.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	n := node.New(path)
+	n.Run()
+	t.Cleanup(func() {
+		go n.Wait()
+		n.Terminate("test")
+	})
+	return n
+}
+
+// WaitFor
+// @Description: 轮询直到条件满足,超过3秒测试失败
+// @param t
+// @param cond
+func WaitFor(t testing.TB, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not reached")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -35,7 +35,7 @@ func udpDial(node api.INode, opts *Options, network, addr string) {
 	protoAddr := fmt.Sprintf("%v://%v", network, addr)
 	server := newUdpServer(node, api.NetConnector, opts, protoAddr)
 	raw := dial(server, network, addr)
-	entity := newEntity(server, opts, raw)
+	entity := newEntity(node, server, opts, raw)
 	server.Link(entity, raw)
 }
 
@@ -21,9 +21,10 @@ import (
 
 var autoId atomic.Uint64
 
-func newEntity(server api.INetServer, opts *Options, rawCon gnet.Conn) api.INetEntity {
+func newEntity(node api.INode, server api.INetServer, opts *Options, rawCon gnet.Conn) api.INetEntity {
 	entity := &Entity{
 		id:     autoId.Add(1),
+		node:   node,
 		server: server,
 		rawCon: rawCon,
 		typ:    server.Typ(),
@@ -55,6 +56,7 @@ func newEntity(server api.INetServer, opts *Options, rawCon gnet.Conn) api.INetE
 type Entity struct {
 	api.BuiltinStopper
 	id                uint64
+	node              api.INode
 	server            api.INetServer
 	rawCon            gnet.Conn
 	fsm               IFsmState
@@ -105,11 +107,11 @@ func (s *Entity) exec(packet *packet.NetworkPacket) error {
 }
 
 func (s *Entity) spawnAgent() *api.Error {
-	if api.GetNode() == nil {
+	if s.node == nil {
 		return nil
 	}
-	s.session = api.NewSession(s)
-	pid, err := api.GetNode().System().Spawn(s.opts.AgentProducer, s)
+	s.session = api.NewSession(s.node, s)
+	pid, err := s.node.System().Spawn(s.opts.AgentProducer, s)
 	if err != nil {
 		zlog.Error("entity spawn agent err",
 			zap.Uint64("entityId", s.ID()), zap.Error(err))
@@ -218,11 +220,11 @@ func (s *Entity) Close(reason *api.Error) *api.Error {
 }
 
 func (s *Entity) Closed(err error) *api.Error {
-	if api.GetNode() == nil {
+	if s.node == nil {
 		return nil
 	}
 	if s.agentPid != nil {
-		if wrong := api.GetNode().System().Send(nil, s.agentPid, "Closed", nil); wrong != nil {
+		if wrong := s.node.System().Send(nil, s.agentPid, "Closed", nil); wrong != nil {
 			zlog.Error("entity closed", zap.Uint64("id", s.ID()), zap.Error(err))
 			return wrong
 		}
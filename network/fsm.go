@@ -158,7 +158,7 @@ func (s *workingState) Exec(pkt *packet.NetworkPacket) *api.Error {
 	return s.processDataPack(pkt)
 }
 func (s *workingState) processDataPack(packet *packet.NetworkPacket) *api.Error {
-	if api.GetNode() == nil || api.GetNode().System() == nil {
+	if s.node == nil || s.node.System() == nil {
 		return nil
 	}
 
@@ -183,7 +183,7 @@ func (s *workingState) processDataPack(packet *packet.NetworkPacket) *api.Error
 		Session: session,
 		Data:    msg.Data,
 	}
-	return api.GetNode().System().PostMessage(to, m)
+	return s.node.System().PostMessage(to, m)
 }
 
 func (s *workingState) Next() IFsmState {
@@ -61,7 +61,7 @@ func (b *udpServer) Run() {
 func (b *udpServer) OnTraffic(c gnet.Conn) (action gnet.Action) {
 	entity := b.Ref(c)
 	if entity == nil {
-		entity = newEntity(b, b.opts, c)
+		entity = newEntity(b.node, b, b.opts, c)
 		b.Link(entity, c)
 	}
 	if err := entity.Traffic(c); err != nil {
@@ -112,7 +112,7 @@ func (b *tcpServer) Run() {
 }
 
 func (b *tcpServer) OnOpen(c gnet.Conn) (out []byte, action gnet.Action) {
-	entity := newEntity(b, b.opts, c)
+	entity := newEntity(b.node, b, b.opts, c)
 	b.Link(entity, c)
 	return nil, gnet.None
 }
@@ -203,10 +203,10 @@ func (b *builtinServer) Stop() *api.Error {
 	return nil
 }
 func (b *builtinServer) run(handler gnet.EventHandler) {
-	if api.GetNode() == nil {
+	if b.node == nil {
 		return
 	}
-	api.GetNode().Submit(func() {
+	b.node.Submit(func() {
 		xerror.Assert(gnet.Run(handler, b.protoAddr, b.Options().GNetOpts...))
 	}, nil)
 }
@@ -217,3 +217,11 @@ func (b *builtinServer) Options() *Options {
 func (b *builtinServer) Typ() api.NetEntityType {
 	return b.typ
 }
+
+// ConnectionCount
+// @Description: 当前连接数量,服务未启动返回0
+// @receiver b
+// @return int
+func (b *builtinServer) ConnectionCount() int {
+	return max(b.eng.CountConnections(), 0)
+}
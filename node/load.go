@@ -0,0 +1,74 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: load
+ * @Version: 1.0.0
+ * @Date: 2025/1/23 10:40
+ */
+
+package node
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/asynctime"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+	"time"
+)
+
+const defaultLoadReportInterval = 5 * time.Second
+
+// runLoadReport
+// @Description: 定时把节点负载发布到discovery,node.loadReportInterval配置间隔,小于0不发布
+// @receiver a
+func (a *Node) runLoadReport() {
+	interval := defaultLoadReportInterval
+	if vp := a.viper.Sub("node"); vp != nil && vp.IsSet("loadReportInterval") {
+		interval = vp.GetDuration("loadReportInterval")
+	}
+	if interval <= 0 || a.discovery == nil {
+		return
+	}
+	a.loadTimer = asynctime.Every(interval, func() {
+		a.Submit(a.reportLoad, nil)
+	})
+}
+
+func (a *Node) stopLoadReport() {
+	if a.loadTimer != nil {
+		a.loadTimer.Stop()
+	}
+}
+
+// Load
+// @Description: 当前节点负载
+// @receiver a
+// @return *api.NodeLoad
+func (a *Node) Load() *api.NodeLoad {
+	load := &api.NodeLoad{Goroutines: a.goCount.Load()}
+	if a.actorSystem != nil {
+		actors, backlog := a.actorSystem.Load()
+		load.Actors = int64(actors)
+		load.Mailbox = int64(backlog)
+	}
+	for _, module := range a.modules {
+		if server, ok := module.(api.INetServer); ok {
+			load.Connections += int64(server.ConnectionCount())
+		}
+	}
+	return load
+}
+
+func (a *Node) reportLoad() {
+	load := a.Load()
+	// 复制一份节点信息,配置中的静态meta保持不变
+	base := *a.BaseNode
+	base.Meta = make(map[string]string, len(a.BaseNode.Meta)+4)
+	for k, v := range a.BaseNode.Meta {
+		base.Meta[k] = v
+	}
+	load.WriteMeta(base.Meta)
+	if err := a.discovery.UpdateNode(&base); err != nil {
+		zlog.Warn("node report load err", zap.Any("load", load), zap.Error(err))
+	}
+}
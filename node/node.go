@@ -10,12 +10,18 @@ package node
 
 import (
 	"fmt"
+	"github.com/RussellLuo/timingwheel"
 	"github.com/dingqinghui/gas/actor"
 	"github.com/dingqinghui/gas/api"
 	"github.com/dingqinghui/gas/cluster/discovery"
 	"github.com/dingqinghui/gas/cluster/discovery/provider/consul"
+	"github.com/dingqinghui/gas/cluster/discovery/provider/gossip"
+	"github.com/dingqinghui/gas/cluster/discovery/provider/memory"
+	"github.com/dingqinghui/gas/cluster/discovery/provider/static"
 	"github.com/dingqinghui/gas/cluster/rpc"
+	"github.com/dingqinghui/gas/cluster/rpc/provider/loopback"
 	"github.com/dingqinghui/gas/cluster/rpc/provider/nats"
+	"github.com/dingqinghui/gas/cluster/rpc/provider/tcp"
 	"github.com/dingqinghui/gas/extend/serializer"
 	"github.com/dingqinghui/gas/extend/snowflake"
 	"github.com/dingqinghui/gas/extend/xerror"
@@ -28,15 +34,20 @@ import (
 	"os/signal"
 	"sync/atomic"
 	"syscall"
+	"time"
 )
 
+// New
+// @Description: 创建节点,同一进程可以创建多个节点,模块通过传入的节点访问配置、actor系统和集群.
+// 日志由进程内的节点共用,以第一个节点的log配置为准,最后一个节点停止时关闭,见zlog.Init
+// @param configPath
+// @return api.INode
 func New(configPath string) api.INode {
 	node := &Node{
 		configPath: configPath,
 		BaseNode:   new(api.BaseNode),
 		stopChan:   make(chan string),
 	}
-	api.SetNode(node)
 	node.Init()
 	return node
 }
@@ -58,6 +69,7 @@ type Node struct {
 	goCount     atomic.Int64
 	panicCount  atomic.Uint64
 	pool        *ants.Pool
+	loadTimer   *timingwheel.Timer
 }
 
 func (a *Node) Init() {
@@ -104,8 +116,11 @@ func (a *Node) initBaseNode() {
 	vp := a.viper.Sub("node")
 	a.BaseNode.Name = a.viper.GetString("cluster.name")
 	a.BaseNode.Id = vp.GetUint64("id")
+	a.BaseNode.Address = vp.GetString("address")
+	a.BaseNode.Port = vp.GetInt("port")
 	a.BaseNode.Tags = vp.GetStringSlice("tags")
 	a.BaseNode.Meta = vp.GetStringMapString("meta")
+	a.BaseNode.Meta[api.MetaStartTime] = convertor.ToString(time.Now().UnixNano())
 
 	fmt.Printf("init node id:%d  type:%s\n", a.BaseNode.Id, a.BaseNode.Name)
 
@@ -115,34 +130,63 @@ func (a *Node) initBaseNode() {
 }
 
 func (a *Node) initLogger() {
-	zlog.Init()
+	zlog.Init(a)
 }
 
 func (a *Node) initActorSystem() {
-	a.actorSystem = actor.NewSystem()
+	a.actorSystem = actor.NewSystem(a)
 }
 
 func (a *Node) initSerializer() {
 	a.serializer = serializer.Json
 }
 
+// initDiscovery
+// @Description: cluster.discovery配置发现方式,consul(默认)、memory(进程内)、static(配置文件)、gossip(节点间gossip)
+// @receiver a
 func (a *Node) initDiscovery() {
 	vp := a.GetViper()
 	clusterName := vp.GetString("cluster.name")
-	provider, err := consul.NewConsulProvider()
-	xerror.Assert(err)
-	a.discovery = discovery.New(clusterName, provider)
+	var provider api.IDiscoveryProvider
+	switch vp.GetString("cluster.discovery") {
+	case "memory":
+		provider = memory.NewMemoryProvider()
+	case "static":
+		provider = static.NewStaticProvider(vp)
+	case "gossip":
+		provider = gossip.NewGossipProvider(vp)
+	default:
+		p, err := consul.NewConsulProvider(vp)
+		xerror.Assert(err)
+		provider = p
+	}
+	a.discovery = discovery.New(a, clusterName, provider)
 }
 
+// initRpc
+// @Description: cluster.rpc配置节点间传输方式,nats(默认)、tcp(节点直连)、loopback(进程内)
+// @receiver a
 func (a *Node) initRpc() {
-	msgque := nats.New()
-	a.rpc = rpc.New(msgque)
+	var msgque api.IRpcMessageQue
+	switch a.GetViper().GetString("cluster.rpc") {
+	case "tcp":
+		transport := tcp.New(a.GetViper(), a.discovery)
+		// 注册实际监听的地址
+		a.BaseNode.Address, a.BaseNode.Port = transport.Advertise()
+		msgque = transport
+	case "loopback":
+		msgque = loopback.New(a.GetViper().GetString("cluster.name"), a.GetID())
+	default:
+		msgque = nats.New(a)
+	}
+	a.rpc = rpc.New(a, msgque)
 }
 
 func (a *Node) Run() {
 	for _, module := range a.modules {
 		module.Run()
 	}
+	a.runLoadReport()
 	zlog.Info("node running............")
 }
 
@@ -205,6 +249,7 @@ func (a *Node) Terminate(reason string) {
 }
 
 func (a *Node) terminate(reason string) {
+	a.stopLoadReport()
 	if a.modules != nil {
 		for i := len(a.modules) - 1; i > 0; i-- {
 			module := a.modules[i]
@@ -215,6 +260,10 @@ func (a *Node) terminate(reason string) {
 		}
 	}
 	zlog.Info("node terminate", zap.String("reason", reason))
+	// 日志最先加入,最后停止
+	if len(a.modules) > 0 {
+		_ = a.modules[0].Stop()
+	}
 }
 
 func (a *Node) Submit(fn func(), recoverFun func(err interface{})) {
@@ -235,7 +284,7 @@ func (a *Node) Try(fn func(), reFun func(err interface{})) {
 			if reFun != nil {
 				reFun(err)
 			}
-			zlog.Error("panic", zap.Error(err.(error)), zap.Stack("stack"))
+			zlog.Error("panic", zap.Any("err", err), zap.Stack("stack"))
 		}
 	}()
 	fn()
@@ -0,0 +1,71 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: node_test
+ * @Version: 1.0.0
+ * @Date: 2025/2/5 15:20
+ */
+
+package node_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/cluster/rpc/provider/loopback"
+	"github.com/dingqinghui/gas/internal/testnode"
+)
+
+// Echo
+// @Description: 回复所在节点id
+type Echo struct {
+	api.BuiltinActor
+}
+
+func (e *Echo) Hello(v *string) (*string, *api.Error) {
+	reply := fmt.Sprintf("%s from %d", *v, e.Ctx.System().Node().GetID())
+	return &reply, nil
+}
+
+// newNode
+// @Description: 同一进程中的节点共用第一个节点的日志,两个节点都停止后关闭,负载快速发布
+func newNode(t *testing.T, id uint64, tag string) api.INode {
+	n := testnode.New(t, "multi-node", id, testnode.WithTags(tag), testnode.WithNode("loadReportInterval", "20ms"))
+	n.System().SetTimeout(200 * time.Millisecond)
+	return n
+}
+
+func TestMultiNode(t *testing.T) {
+	a := newNode(t, 11, "gate")
+	b := newNode(t, 12, "chat")
+	testnode.WaitFor(t, func() bool { return len(a.Discovery().GetAll()) == 2 && len(b.Discovery().GetAll()) == 2 })
+	if chat := a.Discovery().GetByKind("chat"); len(chat) != 1 || chat[0].GetID() != b.GetID() {
+		t.Fatalf("chat nodes = %v", chat)
+	}
+	// 各节点定时发布自己的负载
+	testnode.WaitFor(t, func() bool {
+		node := a.Discovery().GetById(b.GetID())
+		return node != nil && node.GetMeta()[api.MetaLoadActors] != ""
+	})
+
+	if _, err := b.System().Spawn(func() api.IActor { return new(Echo) }, nil, api.WithActorName("echo")); err != nil {
+		t.Fatal(err)
+	}
+	echo := &api.Pid{NodeId: b.GetID(), Name: "echo"}
+	in, reply := "hi", ""
+	if err := a.System().Call(nil, echo, "Hello", &in, &reply); err != nil || reply != "hi from 12" {
+		t.Fatalf("call = %s %v", reply, err)
+	}
+
+	bus := loopback.GetBus("multi-node")
+	bus.Partition(a.GetID(), b.GetID())
+	if err := a.System().Call(nil, echo, "Hello", &in, &reply); err == nil {
+		t.Fatal("call across partition succeeded")
+	}
+	bus.Reset()
+	if err := a.System().Call(nil, echo, "Hello", &in, &reply); err != nil {
+		t.Fatalf("call after reset = %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: actor
+ * @Version: 1.0.0
+ * @Date: 2025/1/20 10:40
+ */
+
+package persistence
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+)
+
+type (
+	// IPersistent
+	// @Description: 持久化actor需要实现,ApplyEvent只修改状态,回放时同样调用
+	IPersistent interface {
+		api.IActor
+		PersistenceId() string
+		ApplyEvent(event interface{})
+	}
+
+	// ISnapshotter
+	// @Description: 支持快照的持久化actor
+	ISnapshotter interface {
+		Snapshot() interface{}
+		ApplySnapshot(snapshot interface{})
+	}
+
+	Option  func(o *Options)
+	Options struct {
+		// SnapshotInterval 每多少个事件保存一次快照,0不自动保存
+		SnapshotInterval uint64
+		// DeleteOnSnapshot 保存快照后删除之前的事件
+		DeleteOnSnapshot bool
+	}
+)
+
+func WithSnapshotInterval(interval uint64) Option {
+	return func(o *Options) {
+		o.SnapshotInterval = interval
+	}
+}
+
+func WithDeleteOnSnapshot(delete bool) Option {
+	return func(o *Options) {
+		o.DeleteOnSnapshot = delete
+	}
+}
+
+// PersistentActor
+// @Description: 事件溯源actor,嵌入后在OnInit中调用Recover
+type PersistentActor struct {
+	api.BuiltinActor
+	owner         IPersistent
+	journal       api.IJournal
+	opts          *Options
+	index         uint64
+	snapshotIndex uint64
+	recovering    bool
+}
+
+// Recover
+// @Description: 加载快照并回放之后的事件,在actor的OnInit中调用
+// @receiver p
+// @param ctx
+// @param owner 嵌入PersistentActor的actor
+// @param journal
+// @param opts
+// @return *api.Error
+func (p *PersistentActor) Recover(ctx api.IActorContext, owner IPersistent, journal api.IJournal, opts ...Option) *api.Error {
+	_ = p.BuiltinActor.OnInit(ctx)
+	if journal == nil {
+		return api.ErrJournalIsNil
+	}
+	p.owner = owner
+	p.journal = journal
+	p.opts = new(Options)
+	for _, opt := range opts {
+		opt(p.opts)
+	}
+	p.recovering = true
+	defer func() { p.recovering = false }()
+
+	id := owner.PersistenceId()
+	snapshot, err := journal.LoadSnapshot(id)
+	if err != nil {
+		return err
+	}
+	if snapshotter, ok := owner.(ISnapshotter); ok && snapshot != nil {
+		state, err := decode(snapshot.Type, snapshot.Data)
+		if err != nil {
+			return err
+		}
+		snapshotter.ApplySnapshot(state)
+		p.index = snapshot.Index
+		p.snapshotIndex = snapshot.Index
+	}
+	err = journal.Replay(id, p.index+1, func(e *api.PersistentEvent) *api.Error {
+		if e.Index != p.index+1 {
+			return api.ErrJournalIndex
+		}
+		event, err := decode(e.Type, e.Data)
+		if err != nil {
+			return err
+		}
+		owner.ApplyEvent(event)
+		p.index = e.Index
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	zlog.Debug("persistent actor recovered",
+		zap.String("persistenceId", id), zap.Uint64("index", p.index), zap.Uint64("snapshot", p.snapshotIndex))
+	return nil
+}
+
+// Persist
+// @Description: 写入日志成功后调用ApplyEvent,失败时状态不变
+// @receiver p
+// @param event 必须通过RegisterEvent注册
+// @return *api.Error
+func (p *PersistentActor) Persist(event interface{}) *api.Error {
+	if p.journal == nil {
+		return api.ErrJournalIsNil
+	}
+	if p.recovering {
+		return api.ErrPersistRecovering
+	}
+	name, data, err := encode(event)
+	if err != nil {
+		return err
+	}
+	e := &api.PersistentEvent{Index: p.index + 1, Type: name, Data: data}
+	if err = p.journal.Append(p.owner.PersistenceId(), e); err != nil {
+		return err
+	}
+	p.index = e.Index
+	p.owner.ApplyEvent(event)
+	if p.opts.SnapshotInterval > 0 && p.index-p.snapshotIndex >= p.opts.SnapshotInterval {
+		if err = p.SaveSnapshot(); err != nil {
+			zlog.Error("persistent actor save snapshot",
+				zap.String("persistenceId", p.owner.PersistenceId()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot
+// @Description: 保存当前状态,actor需实现ISnapshotter
+// @receiver p
+// @return *api.Error
+func (p *PersistentActor) SaveSnapshot() *api.Error {
+	if p.journal == nil {
+		return api.ErrJournalIsNil
+	}
+	snapshotter, ok := p.owner.(ISnapshotter)
+	if !ok || p.index == p.snapshotIndex {
+		return nil
+	}
+	name, data, err := encode(snapshotter.Snapshot())
+	if err != nil {
+		return err
+	}
+	id := p.owner.PersistenceId()
+	if err = p.journal.SaveSnapshot(id, &api.PersistentSnapshot{Index: p.index, Type: name, Data: data}); err != nil {
+		return err
+	}
+	p.snapshotIndex = p.index
+	if p.opts.DeleteOnSnapshot {
+		return p.journal.DeleteTo(id, p.index)
+	}
+	return nil
+}
+
+// LastIndex
+// @Description: 最后一个事件的Index
+func (p *PersistentActor) LastIndex() uint64 {
+	return p.index
+}
+
+func (p *PersistentActor) Recovering() bool {
+	return p.recovering
+}
@@ -0,0 +1,59 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: actor_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/20 10:40
+ */
+
+package persistence
+
+import (
+	"testing"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+type Added struct{ N int }
+
+type State struct{ Sum, Cnt int }
+
+// Counter
+// @Description: 累加事件的持久化actor
+type Counter struct {
+	PersistentActor
+	State
+}
+
+func (c *Counter) PersistenceId() string       { return "counter/1" }
+func (c *Counter) ApplyEvent(e interface{})    { c.Sum += e.(*Added).N; c.Cnt++ }
+func (c *Counter) Snapshot() interface{}       { return &c.State }
+func (c *Counter) ApplySnapshot(s interface{}) { c.State = *s.(*State) }
+
+func init() {
+	RegisterEvent(new(Added), new(State))
+}
+
+// recoverCounter
+// @Description: 恢复后再持久化n个事件
+func recoverCounter(t *testing.T, journal api.IJournal, n int, opts ...Option) *Counter {
+	c := new(Counter)
+	if err := c.Recover(nil, c, journal, opts...); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= n; i++ {
+		if err := c.Persist(&Added{N: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func TestMemoryJournal(t *testing.T) {
+	journal := NewMemoryJournal()
+	c := recoverCounter(t, journal, 10, WithSnapshotInterval(4), WithDeleteOnSnapshot(true))
+	recovered := recoverCounter(t, journal, 0)
+	if recovered.State != c.State || recovered.LastIndex() != 10 {
+		t.Fatalf("recovered %+v index %d, want %+v index 10", recovered.State, recovered.LastIndex(), c.State)
+	}
+}
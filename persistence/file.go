@@ -0,0 +1,341 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: file
+ * @Version: 1.0.0
+ * @Date: 2025/1/20 14:10
+ */
+
+package persistence
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/serializer"
+	"github.com/dingqinghui/gas/zlog"
+	"go.uber.org/zap"
+	"hash/crc32"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	journalExt  = ".journal"
+	snapshotExt = ".snapshot"
+	// recordHeadSize 4字节长度 + 4字节crc32
+	recordHeadSize = 8
+	// maxRecordSize 单条记录上限,损坏的长度不会在校验前分配过大内存
+	maxRecordSize = 64 * 1024 * 1024
+)
+
+var _ api.IJournal = &FileJournal{}
+
+// NewFileJournal
+// @Description: 文件日志,每个persistenceId一个追加写的事件文件和一个快照文件
+// @param dir
+// @param sync 每次写入后刷盘
+// @return *FileJournal
+// @return *api.Error
+func NewFileJournal(dir string, sync bool) (*FileJournal, *api.Error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		zlog.Error("file journal mkdir", zap.String("dir", dir), zap.Error(err))
+		return nil, api.ErrJournal
+	}
+	return &FileJournal{
+		dir:   dir,
+		sync:  sync,
+		files: make(map[string]*journalFile),
+	}, nil
+}
+
+type journalFile struct {
+	file      *os.File
+	lastIndex uint64
+}
+
+type FileJournal struct {
+	sync.Mutex
+	dir   string
+	sync  bool
+	files map[string]*journalFile
+}
+
+func (f *FileJournal) path(persistenceId, ext string) string {
+	return filepath.Join(f.dir, url.PathEscape(persistenceId)+ext)
+}
+
+// open
+// @Description: 首次写入时扫描文件,截断不完整的尾部记录
+// @receiver f
+// @param persistenceId
+// @return *journalFile
+// @return *api.Error
+func (f *FileJournal) open(persistenceId string) (*journalFile, *api.Error) {
+	if jf, ok := f.files[persistenceId]; ok {
+		return jf, nil
+	}
+	file, err := os.OpenFile(f.path(persistenceId, journalExt), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, f.error("open", persistenceId, err)
+	}
+	jf := &journalFile{file: file}
+	valid, err := scanRecords(file, func(event *api.PersistentEvent) *api.Error {
+		jf.lastIndex = event.Index
+		return nil
+	})
+	if err == nil {
+		err = file.Truncate(valid)
+	}
+	if err == nil {
+		_, err = file.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, f.error("open", persistenceId, err)
+	}
+	// 事件已全部删除,从快照的Index继续
+	if jf.lastIndex == 0 {
+		snapshot, e := f.loadSnapshot(persistenceId)
+		if e != nil {
+			_ = file.Close()
+			return nil, e
+		}
+		if snapshot != nil {
+			jf.lastIndex = snapshot.Index
+		}
+	}
+	f.files[persistenceId] = jf
+	return jf, nil
+}
+
+func (f *FileJournal) Append(persistenceId string, event *api.PersistentEvent) *api.Error {
+	f.Lock()
+	defer f.Unlock()
+	jf, e := f.open(persistenceId)
+	if e != nil {
+		return e
+	}
+	if event.Index != jf.lastIndex+1 {
+		return api.ErrJournalIndex
+	}
+	record, e := encodeRecord(event)
+	if e != nil {
+		return e
+	}
+	if _, err := jf.file.Write(record); err != nil {
+		return f.error("append", persistenceId, err)
+	}
+	if f.sync {
+		if err := jf.file.Sync(); err != nil {
+			return f.error("sync", persistenceId, err)
+		}
+	}
+	jf.lastIndex = event.Index
+	return nil
+}
+
+func (f *FileJournal) Replay(persistenceId string, fromIndex uint64, fn func(event *api.PersistentEvent) *api.Error) *api.Error {
+	f.Lock()
+	defer f.Unlock()
+	file, err := os.Open(f.path(persistenceId, journalExt))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return f.error("replay", persistenceId, err)
+	}
+	defer file.Close()
+	var replayErr *api.Error
+	_, err = scanRecords(file, func(event *api.PersistentEvent) *api.Error {
+		if event.Index < fromIndex {
+			return nil
+		}
+		replayErr = fn(event)
+		return replayErr
+	})
+	if replayErr != nil {
+		return replayErr
+	}
+	if err != nil {
+		return f.error("replay", persistenceId, err)
+	}
+	return nil
+}
+
+// DeleteTo
+// @Description: 重写事件文件,只保留toIndex之后的事件
+// @receiver f
+// @param persistenceId
+// @param toIndex
+// @return *api.Error
+func (f *FileJournal) DeleteTo(persistenceId string, toIndex uint64) *api.Error {
+	f.Lock()
+	defer f.Unlock()
+	jf, e := f.open(persistenceId)
+	if e != nil {
+		return e
+	}
+	if _, err := jf.file.Seek(0, io.SeekStart); err != nil {
+		return f.error("delete", persistenceId, err)
+	}
+	var buf []byte
+	_, err := scanRecords(jf.file, func(event *api.PersistentEvent) *api.Error {
+		if event.Index <= toIndex {
+			return nil
+		}
+		record, e := encodeRecord(event)
+		if e != nil {
+			return e
+		}
+		buf = append(buf, record...)
+		return nil
+	})
+	if err != nil {
+		return f.error("delete", persistenceId, err)
+	}
+	path := f.path(persistenceId, journalExt)
+	if err = writeFileAtomic(path, buf); err != nil {
+		return f.error("delete", persistenceId, err)
+	}
+	lastIndex := jf.lastIndex
+	_ = jf.file.Close()
+	delete(f.files, persistenceId)
+	jf, e = f.open(persistenceId)
+	if e != nil {
+		return e
+	}
+	if jf.lastIndex < lastIndex {
+		jf.lastIndex = lastIndex
+	}
+	return nil
+}
+
+func (f *FileJournal) SaveSnapshot(persistenceId string, snapshot *api.PersistentSnapshot) *api.Error {
+	f.Lock()
+	defer f.Unlock()
+	data, err := serializer.Json.Marshal(snapshot)
+	if err != nil {
+		return api.ErrMarshal
+	}
+	if err = writeFileAtomic(f.path(persistenceId, snapshotExt), data); err != nil {
+		return f.error("snapshot", persistenceId, err)
+	}
+	return nil
+}
+
+func (f *FileJournal) LoadSnapshot(persistenceId string) (*api.PersistentSnapshot, *api.Error) {
+	f.Lock()
+	defer f.Unlock()
+	return f.loadSnapshot(persistenceId)
+}
+
+func (f *FileJournal) loadSnapshot(persistenceId string) (*api.PersistentSnapshot, *api.Error) {
+	data, err := os.ReadFile(f.path(persistenceId, snapshotExt))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, f.error("load snapshot", persistenceId, err)
+	}
+	snapshot := new(api.PersistentSnapshot)
+	if err = serializer.Json.Unmarshal(data, snapshot); err != nil {
+		return nil, api.ErrUnmarshal
+	}
+	return snapshot, nil
+}
+
+// Close
+// @Description: 关闭所有打开的事件文件
+// @receiver f
+// @return *api.Error
+func (f *FileJournal) Close() *api.Error {
+	f.Lock()
+	defer f.Unlock()
+	for id, jf := range f.files {
+		_ = jf.file.Close()
+		delete(f.files, id)
+	}
+	return nil
+}
+
+func (f *FileJournal) error(op, persistenceId string, err error) *api.Error {
+	zlog.Error("file journal", zap.String("op", op), zap.String("persistenceId", persistenceId), zap.Error(err))
+	return api.ErrJournal
+}
+
+func encodeRecord(event *api.PersistentEvent) ([]byte, *api.Error) {
+	payload, err := serializer.Json.Marshal(event)
+	if err != nil {
+		return nil, api.ErrMarshal
+	}
+	if len(payload) > maxRecordSize {
+		return nil, api.ErrJournal
+	}
+	record := make([]byte, recordHeadSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeadSize:], payload)
+	return record, nil
+}
+
+var errStopScan = errors.New("stop scan")
+
+// scanRecords
+// @Description: 顺序读取记录,遇到不完整或校验失败的记录停止
+// @param r
+// @param f
+// @return int64 完整记录的长度
+// @return error
+func scanRecords(r io.Reader, f func(event *api.PersistentEvent) *api.Error) (int64, error) {
+	reader := bufio.NewReader(r)
+	var valid int64
+	head := make([]byte, recordHeadSize)
+	for {
+		if _, err := io.ReadFull(reader, head); err != nil {
+			return valid, nil
+		}
+		size := binary.BigEndian.Uint32(head[0:4])
+		if size > maxRecordSize {
+			return valid, nil
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return valid, nil
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(head[4:8]) {
+			return valid, nil
+		}
+		event := new(api.PersistentEvent)
+		if err := serializer.Json.Unmarshal(payload, event); err != nil {
+			return valid, nil
+		}
+		if err := f(event); err != nil {
+			return valid, errStopScan
+		}
+		valid += int64(recordHeadSize + len(payload))
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
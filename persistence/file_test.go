@@ -0,0 +1,77 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: file_test
+ * @Version: 1.0.0
+ * @Date: 2025/1/20 14:10
+ */
+
+package persistence
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/dingqinghui/gas/api"
+)
+
+func openFileJournal(t *testing.T, dir string) *FileJournal {
+	journal, err := NewFileJournal(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = journal.Close() })
+	return journal
+}
+
+func appendTail(t *testing.T, journal *FileJournal, tail []byte) {
+	file, err := os.OpenFile(journal.path("counter/1", journalExt), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.Write(tail)
+	_ = file.Close()
+}
+
+func TestFileJournal(t *testing.T) {
+	dir := t.TempDir()
+	journal := openFileJournal(t, dir)
+	c := recoverCounter(t, journal, 8, WithSnapshotInterval(4), WithDeleteOnSnapshot(true))
+	_ = journal.Close()
+
+	journal = openFileJournal(t, dir)
+	recovered := recoverCounter(t, journal, 0)
+	if recovered.State != c.State || recovered.LastIndex() != 8 {
+		t.Fatalf("recovered %+v index %d, want %+v index 8", recovered.State, recovered.LastIndex(), c.State)
+	}
+	_ = journal.Close()
+
+	// 写入中断的尾部记录被截断
+	appendTail(t, journal, []byte{0, 0, 0, 50, 1, 2})
+	journal = openFileJournal(t, dir)
+	recovered = recoverCounter(t, journal, 1)
+	if recovered.LastIndex() != 9 || recovered.Cnt != 9 {
+		t.Fatalf("after torn tail index %d count %d", recovered.LastIndex(), recovered.Cnt)
+	}
+}
+
+func TestFileJournalCorruptSize(t *testing.T) {
+	dir := t.TempDir()
+	journal := openFileJournal(t, dir)
+	recoverCounter(t, journal, 2)
+	_ = journal.Close()
+
+	head := make([]byte, recordHeadSize)
+	binary.BigEndian.PutUint32(head[0:4], 0xFFFFFFF0)
+	appendTail(t, journal, head)
+	journal = openFileJournal(t, dir)
+	var events []*api.PersistentEvent
+	err := journal.Replay("counter/1", 0, func(event *api.PersistentEvent) *api.Error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("replay = %v, %d events", err, len(events))
+	}
+}
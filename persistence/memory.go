@@ -0,0 +1,107 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: memory
+ * @Version: 1.0.0
+ * @Date: 2025/1/20 11:20
+ */
+
+package persistence
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"sync"
+)
+
+var _ api.IJournal = &MemoryJournal{}
+
+// NewMemoryJournal
+// @Description: 内存日志,用于测试
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{
+		dict: make(map[string]*memoryLog),
+	}
+}
+
+type memoryLog struct {
+	events    []*api.PersistentEvent
+	lastIndex uint64
+	snapshot  *api.PersistentSnapshot
+}
+
+type MemoryJournal struct {
+	sync.RWMutex
+	dict map[string]*memoryLog
+}
+
+func (m *MemoryJournal) getLog(persistenceId string) *memoryLog {
+	l, ok := m.dict[persistenceId]
+	if !ok {
+		l = new(memoryLog)
+		m.dict[persistenceId] = l
+	}
+	return l
+}
+
+func (m *MemoryJournal) Append(persistenceId string, event *api.PersistentEvent) *api.Error {
+	m.Lock()
+	defer m.Unlock()
+	l := m.getLog(persistenceId)
+	if event.Index != l.lastIndex+1 {
+		return api.ErrJournalIndex
+	}
+	l.events = append(l.events, event)
+	l.lastIndex = event.Index
+	return nil
+}
+
+func (m *MemoryJournal) Replay(persistenceId string, fromIndex uint64, f func(event *api.PersistentEvent) *api.Error) *api.Error {
+	m.RLock()
+	l, ok := m.dict[persistenceId]
+	var events []*api.PersistentEvent
+	if ok {
+		events = append(events, l.events...)
+	}
+	m.RUnlock()
+	for _, event := range events {
+		if event.Index < fromIndex {
+			continue
+		}
+		if err := f(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryJournal) DeleteTo(persistenceId string, toIndex uint64) *api.Error {
+	m.Lock()
+	defer m.Unlock()
+	l, ok := m.dict[persistenceId]
+	if !ok {
+		return nil
+	}
+	i := 0
+	for i < len(l.events) && l.events[i].Index <= toIndex {
+		i++
+	}
+	l.events = append([]*api.PersistentEvent(nil), l.events[i:]...)
+	return nil
+}
+
+func (m *MemoryJournal) SaveSnapshot(persistenceId string, snapshot *api.PersistentSnapshot) *api.Error {
+	m.Lock()
+	defer m.Unlock()
+	m.getLog(persistenceId).snapshot = snapshot
+	return nil
+}
+
+func (m *MemoryJournal) LoadSnapshot(persistenceId string) (*api.PersistentSnapshot, *api.Error) {
+	m.RLock()
+	defer m.RUnlock()
+	l, ok := m.dict[persistenceId]
+	if !ok {
+		return nil, nil
+	}
+	return l.snapshot, nil
+}
@@ -0,0 +1,68 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: registry
+ * @Version: 1.0.0
+ * @Date: 2025/1/20 10:20
+ */
+
+package persistence
+
+import (
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/extend/reflectx"
+	"github.com/dingqinghui/gas/extend/serializer"
+	"reflect"
+	"sync"
+)
+
+var registry sync.Map
+
+// RegisterEvent
+// @Description: 注册事件和快照类型,回放时按类型名还原,在actor启动前注册,统一使用json序列化
+// @param prototypes
+func RegisterEvent(prototypes ...interface{}) {
+	for _, prototype := range prototypes {
+		registry.Store(reflectx.TypeFullName(prototype), reflect.TypeOf(prototype))
+	}
+}
+
+func typeName(v interface{}) (string, bool) {
+	name := reflectx.TypeFullName(v)
+	_, ok := registry.Load(name)
+	return name, ok
+}
+
+func encode(v interface{}) (string, []byte, *api.Error) {
+	name, ok := typeName(v)
+	if !ok {
+		return "", nil, api.ErrPersistentEventType
+	}
+	data, err := serializer.Json.Marshal(v)
+	if err != nil {
+		return "", nil, api.ErrMarshal
+	}
+	return name, data, nil
+}
+
+// decode
+// @Description: 按注册的类型还原,注册的是指针类型返回指针
+func decode(name string, data []byte) (interface{}, *api.Error) {
+	v, ok := registry.Load(name)
+	if !ok {
+		return nil, api.ErrPersistentEventType
+	}
+	typ := v.(reflect.Type)
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+	value := reflect.New(typ)
+	if err := serializer.Json.Unmarshal(data, value.Interface()); err != nil {
+		return nil, api.ErrUnmarshal
+	}
+	if isPtr {
+		return value.Interface(), nil
+	}
+	return value.Elem().Interface(), nil
+}
@@ -17,8 +17,7 @@ import (
 	"path/filepath"
 )
 
-func initConfig() *config {
-	node := api.GetNode()
+func initConfig(node api.INode) *config {
 	if node == nil {
 		return nil
 	}
@@ -14,9 +14,12 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
+	"sync"
+	"sync/atomic"
 )
 
 type ZLogger struct {
+	node        api.INode
 	cfg         *config
 	logger      *zap.Logger
 	sugarLogger *zap.SugaredLogger
@@ -25,10 +28,10 @@ type ZLogger struct {
 }
 
 func (z *ZLogger) Init() {
-	if api.GetNode() == nil {
+	if z.node == nil {
 		return
 	}
-	z.cfg = initConfig()
+	z.cfg = initConfig(z.node)
 	if z.cfg == nil {
 		return
 	}
@@ -62,7 +65,7 @@ func (z *ZLogger) Init() {
 		zap.AddCaller(),
 		zap.AddStacktrace(zap.DPanicLevel),
 		zap.AddCallerSkip(1),
-		zap.Fields(zap.String("nodeId", convertor.ToString(api.GetNode().GetID()))),
+		zap.Fields(zap.String("nodeId", convertor.ToString(z.node.GetID()))),
 	}
 	options = append(options, z.cfg.getZapOption()...)
 	z.logger = zap.New(mulCore, options...)
@@ -80,68 +83,126 @@ func (z *ZLogger) Stop() *api.Error {
 	return nil
 }
 
-var log *ZLogger
+var (
+	log     atomic.Pointer[ZLogger]
+	logRefs int
+	logLock sync.Mutex
+)
+
+// Init
+// @Description: 初始化进程日志,同一进程中有多个节点时共用第一个初始化的节点的日志.
+// 日志路径、级别、是否输出到控制台和日志中的nodeId字段都以第一个节点的配置为准,后续节点的log配置不生效;
+// 每个节点持有一个引用,最后一个节点停止时刷新并关闭日志,之后创建的节点重新初始化
+// @param node
+func Init(node api.INode) {
+	if node == nil {
+		return
+	}
+	logLock.Lock()
+	defer logLock.Unlock()
+	if logRefs == 0 {
+		logger := &ZLogger{node: node}
+		logger.Init()
+		log.Store(logger)
+	}
+	logRefs++
+	node.AddModule(new(nodeLogger))
+}
 
-func Init() {
-	if api.GetNode() == nil {
+// release
+// @Description: 释放一个节点的引用,没有节点使用时关闭日志
+func release() {
+	logLock.Lock()
+	defer logLock.Unlock()
+	if logRefs <= 0 {
+		return
+	}
+	logRefs--
+	if logRefs > 0 {
 		return
 	}
-	log = new(ZLogger)
-	log.Init()
-	api.GetNode().AddModule(log)
+	if logger := log.Swap(nil); logger != nil {
+		_ = logger.Stop()
+	}
+}
+
+// nodeLogger
+// @Description: 节点持有的进程日志引用,节点停止时释放
+type nodeLogger struct {
+	api.BuiltinModule
+}
+
+func (l *nodeLogger) Name() string {
+	return "logger"
+}
+
+func (l *nodeLogger) Stop() *api.Error {
+	if err := l.BuiltinStopper.Stop(); err != nil {
+		return err
+	}
+	release()
+	return nil
 }
 
 func Debug(msg string, fields ...zap.Field) {
-	if log == nil || log.logger == nil {
+	l := log.Load()
+	if l == nil || l.logger == nil {
 		return
 	}
-	log.logger.Debug(msg, fields...)
+	l.logger.Debug(msg, fields...)
 }
 
 func Info(msg string, fields ...zap.Field) {
-	if log == nil || log.logger == nil {
+	l := log.Load()
+	if l == nil || l.logger == nil {
 		return
 	}
-	log.logger.Info(msg, fields...)
+	l.logger.Info(msg, fields...)
 }
 
 func Warn(msg string, fields ...zap.Field) {
-	if log == nil || log.logger == nil {
+	l := log.Load()
+	if l == nil || l.logger == nil {
 		return
 	}
-	log.logger.Warn(msg, fields...)
+	l.logger.Warn(msg, fields...)
 }
 
 func Error(msg string, fields ...zap.Field) {
-	if log == nil || log.logger == nil {
+	l := log.Load()
+	if l == nil || l.logger == nil {
 		return
 	}
-	log.logger.Error(msg, fields...)
+	l.logger.Error(msg, fields...)
 }
 
 func Panic(msg string, fields ...zap.Field) {
-	if log == nil || log.logger == nil {
+	l := log.Load()
+	if l == nil || l.logger == nil {
 		return
 	}
-	log.logger.DPanic(msg, fields...)
+	l.logger.DPanic(msg, fields...)
 }
 
 func Fatal(msg string, fields ...zap.Field) {
-	if log == nil || log.logger == nil {
+	l := log.Load()
+	if l == nil || l.logger == nil {
 		return
 	}
-	log.logger.Fatal(msg, fields...)
+	l.logger.Fatal(msg, fields...)
 }
 func SetLogLevel(logLevel zapcore.Level) {
-	if log == nil {
+	l := log.Load()
+	if l == nil {
 		return
 	}
-	log.loglevel.SetLevel(logLevel)
+	l.loglevel.SetLevel(logLevel)
 }
 
 func GetLevel() zapcore.Level {
-	if log == nil {
+	l := log.Load()
+	if l == nil {
 		return zapcore.DebugLevel
 	}
-	return log.loglevel.Level()
+	return l.loglevel.Level()
 }
@@ -0,0 +1,81 @@
+/**
+ * @Author: dingQingHui
+ * @Description:
+ * @File: logger_test
+ * @Version: 1.0.0
+ * @Date: 2025/2/7 10:30
+ */
+
+package zlog_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dingqinghui/gas/api"
+	"github.com/dingqinghui/gas/node"
+	"github.com/dingqinghui/gas/zlog"
+)
+
+// newNode
+// @Description: 创建运行的节点,返回节点和日志文件路径
+func newNode(t *testing.T, id uint64) (api.INode, string) {
+	dir := t.TempDir()
+	config := map[string]interface{}{
+		"cluster": map[string]interface{}{"name": "zlog", "discovery": "memory", "rpc": "loopback"},
+		"log":     map[string]interface{}{"path": dir, "printConsole": false},
+		"node":    map[string]interface{}{"id": id},
+	}
+	data, _ := json.Marshal(config)
+	path := filepath.Join(dir, "node.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	n := node.New(path)
+	n.Run()
+	return n, filepath.Join(dir, strconv.FormatUint(id, 10), "log")
+}
+
+func stopNode(n api.INode) {
+	done := make(chan struct{})
+	go func() {
+		n.Wait()
+		close(done)
+	}()
+	n.Terminate("test")
+	<-done
+}
+
+func logged(path, msg string) bool {
+	data, _ := os.ReadFile(path)
+	return strings.Contains(string(data), msg)
+}
+
+func TestSharedLogger(t *testing.T) {
+	a, aLog := newNode(t, 1)
+	b, _ := newNode(t, 2)
+
+	// 第一个节点停止后其他节点继续使用日志
+	stopNode(a)
+	zlog.Info("after first stop")
+	if !logged(aLog, "after first stop") {
+		t.Fatal("logger stopped with the first node")
+	}
+
+	// 最后一个节点停止后关闭,之后的节点重新初始化
+	stopNode(b)
+	zlog.Info("after all stop")
+	if logged(aLog, "after all stop") {
+		t.Fatal("logger not stopped with the last node")
+	}
+	c, cLog := newNode(t, 3)
+	defer stopNode(c)
+	zlog.Info("new node")
+	if !logged(cLog, "new node") || logged(aLog, "new node") {
+		t.Fatal("logger not initialized by the new node")
+	}
+}